@@ -0,0 +1,37 @@
+package main
+
+import "fmt"
+
+type T struct{ Name string }
+
+func f(x any) any { return x }
+
+func g(v interface{}) string {
+	switch x := v.(type) {
+	case int:
+		return fmt.Sprint("int ", x)
+	case string:
+		return "string " + x
+	case any:
+		return fmt.Sprintf("any %v", x)
+	}
+	return "nil"
+}
+
+func main() {
+	var a any = 3
+	var b interface{} = a
+	a = b
+	m := map[string]any{"a": 1, "b": "two"}
+	s := []any{1, "x", T{"t"}}
+	fmt.Println(a, m["a"], m["b"], s)
+	fmt.Println(g(f(2)), g(f("hello")), g(f(T{"bob"})), g(nil))
+	if v, ok := b.(any); ok {
+		fmt.Println("ok", v)
+	}
+}
+
+// Output:
+// 3 1 two [1 x {t}]
+// int 2 string hello any {bob} nil
+// ok 3
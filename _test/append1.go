@@ -0,0 +1,46 @@
+package main
+
+import "fmt"
+
+type Ints []int
+
+type Str string
+
+func sum(base []int, xs ...int) []int { return append(base, xs...) }
+
+func main() {
+	s := append([]int{1})
+	s = append(s, []int{2, 3}...)
+	fmt.Println(s, sum(nil, 4, 5))
+
+	var is Ints
+	is = append(is, 1, 2)
+	is = append(is, Ints{3}...)
+	fmt.Println(is, len(is))
+
+	b := append([]byte("a"), []byte("b")...)
+	var st Str = "d"
+	b = append(b, "c"...)
+	b = append(b, st...)
+	fmt.Println(string(b))
+
+	var ss [][]int
+	ss = append(ss, []int{1})
+	fmt.Println(len(ss), ss)
+
+	a := make([]int, 2)
+	c := append(a, 5)
+	c[0] = 9
+	fmt.Println(a, c)
+
+	x := append(s[:1], 7)
+	fmt.Println(x, s)
+}
+
+// Output:
+// [1 2 3] [4 5]
+// [1 2 3] 3
+// abcd
+// 1 [[1]]
+// [0 0] [9 0 5]
+// [1 7] [1 7 3]
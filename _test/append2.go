@@ -0,0 +1,9 @@
+package main
+
+func main() {
+	s := []int{1}
+	s = append(s, []string{"a"}...)
+}
+
+// Error:
+// 5:16: cannot use []string as type []int
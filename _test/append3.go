@@ -0,0 +1,59 @@
+package main
+
+import "fmt"
+
+type P struct{ X int }
+
+func main() {
+	// Appends within capacity share the backing array.
+	s := make([]int, 3, 5)
+	a := append(s, 1)
+	b := append(s, 2)
+	fmt.Println(a, b, len(a), cap(a))
+	a[0] = 9
+	fmt.Println(s[0], b[0])
+
+	// Appends beyond capacity allocate a new backing array.
+	c := append(a, 3, 4)
+	c[1] = 7
+	fmt.Println(a, c, cap(c) > 5)
+
+	t := append(s[1:2], 42)
+	fmt.Println(s, t, cap(t))
+
+	// A full slice expression limits the capacity.
+	u := append(s[1:2:2], 43)
+	u[0] = 100
+	fmt.Println(s, u)
+
+	ps := append([]P(nil), P{1})
+	q := append(ps, P{2})
+	q[0].X = 5
+	fmt.Println(ps, q)
+
+	is := make([]interface{}, 1, 3)
+	j := append(is, 1)
+	k := append(is, "x")
+	fmt.Println(j, k)
+
+	bs := make([]byte, 2, 4)
+	copy(bs, "ab")
+	bs2 := append(bs[:1], "cd"...)
+	fmt.Println(string(bs), string(bs2))
+
+	var ar [4]int
+	sl := append(ar[:2], 5)
+	sl[0] = 1
+	fmt.Println(ar, sl)
+}
+
+// Output:
+// [0 0 0 2] [0 0 0 2] 4 5
+// 9 9
+// [9 0 0 2] [9 7 0 2 3 4] true
+// [9 0 42] [0 42] 4
+// [9 0 42] [100 43]
+// [{1}] [{5} {2}]
+// [<nil> x] [<nil> x]
+// ac acd
+// [1 0 5 0] [1 0 5]
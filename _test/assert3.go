@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	var x interface{} = errors.New("boom")
+	if err, ok := x.(error); ok {
+		fmt.Println("error:", err)
+	}
+	_, ok := x.(fmt.Stringer)
+	fmt.Println(ok)
+
+	x = 3
+	_, ok = x.(error)
+	fmt.Println(ok)
+
+	x = nil
+	_, ok = x.(error)
+	fmt.Println(ok)
+
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		fmt.Println(ok, err)
+	}()
+	panic(errors.New("panic error"))
+}
+
+// Output:
+// error: boom
+// false
+// false
+// false
+// true panic error
@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+func main() {
+	for i := 0; i < 5; i++ {
+		switch {
+		case i == 1:
+			break
+		case i == 3:
+			continue
+		}
+		fmt.Println("i", i)
+	}
+
+outer:
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			switch j {
+			case 1:
+				continue outer
+			case 2:
+				break outer
+			}
+			fmt.Println(i, j)
+		}
+	}
+
+loop:
+	for _, s := range []string{"a", "b", "c", "d"} {
+		switch s {
+		case "b":
+			continue loop
+		case "d":
+			break loop
+		}
+		fmt.Println(s)
+	}
+}
+
+// Output:
+// i 0
+// i 1
+// i 2
+// i 4
+// 0 0
+// 1 0
+// 2 0
+// a
+// c
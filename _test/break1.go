@@ -0,0 +1,97 @@
+package main
+
+import "fmt"
+
+func main() {
+	// labeled switch
+sw:
+	switch x := 2; x {
+	case 2:
+		for i := 0; i < 3; i++ {
+			if i == 1 {
+				break sw
+			}
+			fmt.Println("sw", i)
+		}
+		fmt.Println("not reached")
+	}
+	fmt.Println("after sw")
+
+	// labeled select
+	c := make(chan int, 1)
+	c <- 1
+sel:
+	select {
+	case v := <-c:
+		for {
+			if v > 0 {
+				break sel
+			}
+		}
+	}
+	fmt.Println("after sel")
+
+	// labeled block with goto
+	n := 0
+blk:
+	{
+		n++
+		if n < 3 {
+			goto blk
+		}
+	}
+	fmt.Println("blk", n)
+
+	// nested labeled loops, continue outer from inner switch in inner loop
+	count := 0
+outer:
+	for i := 0; i < 3; i++ {
+	inner:
+		for j := 0; j < 3; j++ {
+			switch {
+			case j == 1 && i == 1:
+				continue outer
+			case j == 2:
+				break inner
+			}
+			count += 10*i + j
+		}
+		fmt.Println("end inner", i)
+	}
+	fmt.Println("count", count)
+
+	// labeled range over map and continue with post statement
+	s := 0
+loop:
+	for k := range map[int]bool{1: true, 2: true, 3: true} {
+		for j := 0; j < 2; j++ {
+			if k == 2 {
+				continue loop
+			}
+		}
+		s += k
+	}
+	fmt.Println("s", s)
+
+	// continue label with post statement in 3-clause loop
+	m := 0
+post:
+	for i := 0; i < 4; i++ {
+		for {
+			m++
+			continue post
+		}
+	}
+	fmt.Println("m", m)
+}
+
+// Output:
+// sw 0
+// after sw
+// after sel
+// blk 3
+// end inner 0
+// end inner 2
+// count 52
+// s 4
+// m 4
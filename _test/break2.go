@@ -0,0 +1,108 @@
+package main
+
+import "fmt"
+
+func main() {
+	c := make(chan int, 10)
+	for i := 0; i < 5; i++ {
+		c <- i
+	}
+	close(c)
+
+	// labeled range over channel, break from select
+	sum := 0
+rc:
+	for v := range c {
+		select {
+		default:
+			if v == 3 {
+				break rc
+			}
+			sum += v
+		}
+	}
+	fmt.Println("sum", sum)
+
+	// unlabeled break in select only exits the select
+	d := make(chan int, 1)
+	n := 0
+	for i := 0; i < 3; i++ {
+		d <- i
+		select {
+		case v := <-d:
+			if v == 1 {
+				break
+			}
+			n += 10
+		}
+		n++
+	}
+	fmt.Println("n", n)
+
+	// labeled type switch
+	var x interface{} = 1
+ts:
+	switch x.(type) {
+	case int:
+		for {
+			break ts
+		}
+	}
+	fmt.Println("after ts")
+
+	// continue outer loop from within type switch
+	t := 0
+lp:
+	for _, v := range []interface{}{1, "a", 2.0} {
+		switch v.(type) {
+		case string:
+			continue lp
+		}
+		t++
+	}
+	fmt.Println("t", t)
+
+	// three nested loops
+	r := 0
+l1:
+	for i := 0; i < 3; i++ {
+	l2:
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				if k == 1 {
+					continue l2
+				}
+				if j == 2 {
+					continue l1
+				}
+				if i == 2 {
+					break l1
+				}
+				r++
+			}
+		}
+	}
+	fmt.Println("r", r)
+
+	// labeled for without condition, break from switch in inner loop
+	u := 0
+l3:
+	for {
+		for {
+			switch {
+			case u > 5:
+				break l3
+			}
+			u++
+		}
+	}
+	fmt.Println("u", u)
+}
+
+// Output:
+// sum 3
+// n 23
+// after ts
+// t 2
+// r 4
+// u 6
@@ -0,0 +1,11 @@
+package main
+
+func main() {
+L:
+	{
+		break L
+	}
+}
+
+// Error:
+// 6:9: invalid break label L
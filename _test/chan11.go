@@ -0,0 +1,31 @@
+package main
+
+import "fmt"
+
+type T struct{ A int }
+
+func main() {
+	c := make(chan interface{})
+	go func() {
+		c <- T{1}
+		c <- "x"
+		c <- nil
+		close(c)
+	}()
+	for v := range c {
+		fmt.Println(v)
+	}
+
+	d := make(chan interface{}, 1)
+	d <- 2
+	select {
+	case v := <-d:
+		fmt.Println(v)
+	}
+}
+
+// Output:
+// {1}
+// x
+// <nil>
+// 2
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+func gen(done <-chan struct{}, n int) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for i := 1; i <= n; i++ {
+			select {
+			case out <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func square(done <-chan struct{}, in <-chan int) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for v := range in {
+			select {
+			case out <- v * v:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func merge(done <-chan struct{}, cs ...<-chan int) <-chan int {
+	var wg sync.WaitGroup
+	out := make(chan int)
+	wg.Add(len(cs))
+	for _, c := range cs {
+		go func(c <-chan int) {
+			defer wg.Done()
+			for v := range c {
+				select {
+				case out <- v:
+				case <-done:
+					return
+				}
+			}
+		}(c)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// settle waits for the number of goroutines to go back to n.
+func settle(n int) bool {
+	for i := 0; i < 100; i++ {
+		if runtime.NumGoroutine() <= n {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func main() {
+	base := runtime.NumGoroutine()
+
+	// Full pipeline, drained until close.
+	done := make(chan struct{})
+	in := gen(done, 10)
+	sum := 0
+	for v := range merge(done, square(done, in), square(done, in)) {
+		sum += v
+	}
+	close(done)
+	fmt.Println(sum, settle(base))
+
+	// Pipeline cancelled before completion.
+	done = make(chan struct{})
+	out := square(done, gen(done, 1000))
+	fmt.Println(<-out, <-out)
+	close(done)
+	fmt.Println(settle(base))
+}
+
+// Output:
+// 385 true
+// 1 4
+// true
@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+type T struct{ I int }
+
+func main() {
+	var t T
+	var fns []func() int
+	for i := 0; i < 3; i++ {
+		fns = append(fns, func() int { return i + t.I })
+	}
+	t = T{10}
+	for _, f := range fns {
+		fmt.Println(f())
+	}
+}
+
+// Output:
+// 10
+// 11
+// 12
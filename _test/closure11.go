@@ -0,0 +1,44 @@
+package main
+
+import "fmt"
+
+var gfib func(int) int
+
+func init() {
+	gfib = func(n int) int {
+		if n < 2 {
+			return n
+		}
+		return gfib(n-1) + gfib(n-2)
+	}
+}
+
+func main() {
+	var fib func(int) int
+	fib = func(n int) int {
+		if n < 2 {
+			return n
+		}
+		return fib(n-1) + fib(n-2)
+	}
+	fmt.Println(fib(10), gfib(12))
+
+	var walk func(d int) []int
+	walk = func(d int) []int {
+		if d == 0 {
+			return nil
+		}
+		return append(walk(d-1), d)
+	}
+	fmt.Println(walk(4))
+
+	var even, odd func(int) bool
+	even = func(n int) bool { return n == 0 || odd(n-1) }
+	odd = func(n int) bool { return n != 0 && even(n-1) }
+	fmt.Println(even(10), odd(7))
+}
+
+// Output:
+// 55 144
+// [1 2 3 4]
+// true true
@@ -0,0 +1,21 @@
+package main
+
+import "fmt"
+
+func main() {
+	for i := 0; i < 3; i++ {
+		var fact func(int) int
+		fact = func(n int) int {
+			if n <= 1 {
+				return 1
+			}
+			return n * fact(n-1)
+		}
+		fmt.Println(i, fact(i+3))
+	}
+}
+
+// Output:
+// 0 6
+// 1 24
+// 2 120
@@ -0,0 +1,39 @@
+package main
+
+import "fmt"
+
+func pairs(keys []string, vals []int) func() (string, int, bool) {
+	i := 0
+	return func() (string, int, bool) {
+		if i >= len(keys) {
+			return "", 0, false
+		}
+		k, v := keys[i], vals[i]
+		i++
+		return k, v, true
+	}
+}
+
+func main() {
+	next := pairs([]string{"a", "b", "c"}, []int{1, 2, 3})
+	var fns []func() string
+	sum := 0
+	for {
+		k, v, ok := next()
+		if !ok {
+			break
+		}
+		sum += v
+		fns = append(fns, func() string { return fmt.Sprintf("%s=%d", k, v) })
+	}
+	for _, f := range fns {
+		fmt.Println(f())
+	}
+	fmt.Println(sum)
+}
+
+// Output:
+// a=1
+// b=2
+// c=3
+// 6
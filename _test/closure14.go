@@ -0,0 +1,24 @@
+package main
+
+import "fmt"
+
+func main() {
+	var fns []func() int
+	total := 0
+	for i, j := 0, 10; i < 3; i, j = i+1, j+1 {
+		total += j
+		fns = append(fns, func() int { return i * 100 })
+		j++
+		total += j
+	}
+	for _, f := range fns {
+		fmt.Println(f())
+	}
+	fmt.Println(total)
+}
+
+// Output:
+// 0
+// 100
+// 200
+// 75
@@ -0,0 +1,30 @@
+package main
+
+import "fmt"
+
+func main() {
+	var fns []func() string
+	sum := 0
+	for _, s := range []string{"a", "b", "c"} {
+		n := len(sum2(s))
+		c := s + s
+		fns = append(fns, func() string { return fmt.Sprint(c, n) })
+		c += "!"
+		n *= 10
+		m := n + 1
+		m++
+		sum += m
+	}
+	for _, f := range fns {
+		fmt.Println(f())
+	}
+	fmt.Println(sum)
+}
+
+func sum2(s string) string { return s + s }
+
+// Output:
+// aa!20
+// bb!20
+// cc!20
+// 66
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+func main() {
+	items := []string{"a", "b", "c", "d"}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var res []string
+	for i, it := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			res = append(res, fmt.Sprint(i, it))
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	sort.Strings(res)
+	fmt.Println(res)
+
+	ch := make(chan int)
+	for i := 0; i < 3; i++ {
+		go func() { ch <- i }()
+	}
+	ids := []int{<-ch, <-ch, <-ch}
+	sort.Ints(ids)
+	fmt.Println(ids)
+}
+
+// Output:
+// [0a 1b 2c 3d]
+// [0 1 2]
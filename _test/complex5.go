@@ -0,0 +1,55 @@
+package main
+
+import "fmt"
+
+func main() {
+	a := complex(1, 2) * complex(0, 1)
+	fmt.Printf("%v %T\n", a, a)
+	const k = complex(1, 2) * complex(0, 1)
+	fmt.Printf("%v %T\n", k, k)
+	var c64 complex64 = complex(1.5, -2)
+	var c128 complex128 = complex(3, 4)
+	fmt.Printf("%v %T %v %T\n", real(c64), real(c64), imag(c64), imag(c64))
+	fmt.Printf("%v %T %v %T\n", real(c128), real(c128), imag(c128), imag(c128))
+	x := c64 * complex(0, 1)
+	fmt.Printf("%v %T\n", x, x)
+	y := c128 * c128 / complex(0, 2)
+	fmt.Printf("%v %T\n", y, y)
+	var f32 float32 = 2
+	z := complex(f32, f32)
+	fmt.Printf("%v %T\n", z, z)
+	r := real(complex(1, 2))
+	fmt.Printf("%v %T\n", r, r)
+	i := imag(2i)
+	fmt.Printf("%v %T\n", i, i)
+	const rc = real(3 + 4i)
+	var rf float32 = rc
+	fmt.Printf("%v %T %v\n", rf, rf, rc)
+	fmt.Println(complex(1, 2) == 1+2i, real(c64)+imag(c64))
+	var f64 = 1.0
+	w := complex(f64, 2)
+	fmt.Printf("%v %T\n", w, w)
+	v := complex(2, f32)
+	fmt.Printf("%v %T\n", v, v)
+	m := 1 + 2i
+	m *= 1i
+	fmt.Printf("%v %T\n", m, m)
+	fmt.Println(real(m)*2, imag(m)-1)
+}
+
+// Output:
+// (-2+1i) complex128
+// (-2+1i) complex128
+// 1.5 float32 -2 float32
+// 3 float64 4 float64
+// (2+1.5i) complex64
+// (12+3.5i) complex128
+// (2+2i) complex64
+// 1 float64
+// 2 float64
+// 3 float32 3
+// true -0.5
+// (1+2i) complex128
+// (2+2i) complex64
+// (-2+1i) complex128
+// -4 0
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type S string
+
+func main() {
+	b := make([]byte, 3)
+	n := copy(b, "abcdef")
+	fmt.Println(n, string(b))
+
+	s := "xy"
+	n = copy(b[1:], s)
+	fmt.Println(n, string(b))
+
+	c := make([]byte, 10)
+	fmt.Println(copy(c, "hi"), copy(c[2:], b), string(c[:5]))
+	fmt.Println(copy(b, ""), copy([]byte{}, "abc"))
+	fmt.Println(copy(b, strings.ToUpper("pqrs")), copy(b[2:], S("z")), string(b))
+
+	ints := []int{1, 2, 3}
+	fmt.Println(copy(ints, []int{9}), ints)
+}
+
+// Output:
+// 3 abc
+// 2 axy
+// 2 3 hiaxy
+// 0 0
+// 3 1 PQz
+// 1 [9 2 3]
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func work(fail bool) (n int, ok bool, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("work: %w", err)
+			ok = false
+			n = -1
+		}
+	}()
+	if fail {
+		return 3, true, errors.New("failed")
+	}
+	return 3, true, nil
+}
+
+func safe() (res string, recovered bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			res = fmt.Sprint("recovered: ", r)
+			recovered = true
+		}
+	}()
+	panic("oops")
+}
+
+func count() (a, b int) {
+	defer func() { a *= 2; b += a }()
+	a, b = 1, 2
+	return a + 1, b
+}
+
+func main() {
+	fmt.Println(work(false))
+	fmt.Println(work(true))
+	fmt.Println(safe())
+	fmt.Println(count())
+	n, ok, err := work(true)
+	fmt.Println(n, ok, err, errors.Unwrap(err))
+}
+
+// Output:
+// 3 true <nil>
+// -1 false work: failed
+// recovered: oops true
+// 4 6
+// -1 false work: failed failed
@@ -0,0 +1,24 @@
+package main
+
+import "fmt"
+
+func f() {
+	defer fmt.Println("defer 1")
+	defer func() {
+		panic("second")
+	}()
+	defer fmt.Println("defer 3")
+	panic("first")
+}
+
+func main() {
+	defer func() {
+		fmt.Println("recovered:", recover())
+	}()
+	f()
+}
+
+// Output:
+// defer 3
+// defer 1
+// recovered: second
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type T struct{ a int }
+
+func fail() (err error) {
+	defer func() { err = errors.New("deferred") }()
+	return nil
+}
+
+func wrap() (err error) {
+	defer func() { err = fmt.Errorf("wrapped: %v", err) }()
+	return errors.New("base")
+}
+
+func values() (n int, s string, t T) {
+	defer func() {
+		n *= 2
+		s += "!"
+		t.a++
+	}()
+	t = T{1}
+	return 21, "hi", t
+}
+
+func recovered() (n int) {
+	defer func() {
+		if recover() != nil {
+			n = -1
+		}
+	}()
+	panic("boom")
+}
+
+func main() {
+	fmt.Println(fail())
+	fmt.Println(wrap())
+	fmt.Println(values())
+	fmt.Println(recovered())
+	fmt.Println(strings.Map(func(r rune) (o rune) {
+		defer func() { o = r + 1 }()
+		return r
+	}, "abc"))
+}
+
+// Output:
+// deferred
+// wrapped: base
+// 42 hi! {2}
+// -1
+// bcd
@@ -1,21 +1,71 @@
 package main
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
 
-func f1(ch chan string) {
-	defer close(ch)
+type Res struct {
+	name string
+}
 
-	ch <- "foo"
+func (r *Res) Close() error {
+	fmt.Println("close", r.name)
+	return nil
 }
 
-func main() {
-	ch := make(chan string, 1)
-	f1(ch)
+func open(name string) (*Res, error) {
+	if name == "" {
+		return nil, errors.New("empty name")
+	}
+	return &Res{name: name}, nil
+}
+
+func use(name string, fail bool) error {
+	r, err := open(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
 
-	for s := range ch {
-		fmt.Println(s)
+	var c io.Closer
+	c, err = open(name + "2")
+	if err != nil {
+		return err
 	}
+	defer c.Close()
+	r, c = nil, nil
+
+	if fail {
+		return errors.New("early return")
+	}
+	fmt.Println("use", name)
+	return nil
+}
+
+func main() {
+	fmt.Println(use("a", false))
+	fmt.Println(use("b", true))
+	fmt.Println(use("", false))
+
+	rc := ioutil.NopCloser(strings.NewReader("data"))
+	func() {
+		defer rc.Close()
+		rc = nil
+	}()
+	fmt.Println(rc == nil)
 }
 
 // Output:
-// foo
+// use a
+// close a2
+// close a
+// <nil>
+// close b2
+// close b
+// early return
+// empty name
+// true
@@ -0,0 +1,13 @@
+package driver
+
+import "github.com/containous/yaegi/_test/registry"
+
+var version = "1.0"
+
+func init() {
+	registry.Register("mem", "in memory driver "+version)
+}
+
+func init() {
+	registry.Register("null", "null driver")
+}
@@ -0,0 +1,22 @@
+package main
+
+import _ "embed"
+
+//go:embed embed0.txt
+var s string
+
+var (
+	//go:embed "embed0.txt"
+	b []byte
+
+	n = len(s)
+)
+
+func main() {
+	print(s)
+	println(s == string(b), n)
+}
+
+// Output:
+// Hello, embed!
+// true 14
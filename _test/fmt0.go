@@ -0,0 +1,37 @@
+package main
+
+import "fmt"
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('+') {
+			fmt.Fprintf(f, "Point{X:%d, Y:%d}", p.X, p.Y)
+			return
+		}
+		fmt.Fprintf(f, "(%d,%d)", p.X, p.Y)
+	default:
+		w, ok := f.Width()
+		fmt.Fprintf(f, "%%%c width=%d %v", verb, w, ok)
+	}
+}
+
+type Celsius float64
+
+func (c Celsius) String() string { return fmt.Sprintf("%.1f°C", float64(c)) }
+
+func main() {
+	p := Point{1, 2}
+	fmt.Printf("%v %+v %5d\n", p, p, p)
+	fmt.Println(p, &p)
+	fmt.Println(fmt.Sprint(Celsius(21.5)))
+}
+
+// Output:
+// (1,2) Point{X:1, Y:2} %d width=5 true
+// (1,2) (1,2)
+// 21.5°C
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+type S []int
+
+func (s S) String() string { return fmt.Sprintf("S%v", []int(s)) }
+
+type P struct{ X int }
+
+func (p P) String() string { return fmt.Sprintf("P%d", p.X) }
+
+func main() {
+	s := S{3, 1, 2}
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	fmt.Println(s)
+
+	fmt.Println(reflect.DeepEqual(P{1}, P{1}), reflect.DeepEqual(P{1}, P{2}))
+
+	var m sync.Map
+	m.Store("a", P{2})
+	v, _ := m.Load("a")
+	p, ok := v.(P)
+	fmt.Println(p.X, ok, p)
+}
+
+// Output:
+// S[1 2 3]
+// true false
+// 2 true P2
@@ -0,0 +1,26 @@
+package main
+
+import "fmt"
+
+var g = func() int { return 40 + 2 }()
+
+func main() {
+	x := 3
+	r := func() int {
+		y := x * 2
+		return y + 1
+	}()
+	s, u := func(a, b int) (int, string) { return a + b, fmt.Sprint(a, b) }(r, x)
+	q, t := func(a, b int) (int, string) { return a * b, "mul" }(2, 5)
+	func(msg string) { fmt.Println("iife", msg) }("hi")
+	fmt.Println(g, r, s, u, q, t)
+	fmt.Println(func(v ...int) int { return len(v) }(1, 2, 3))
+	defer func(n int) { fmt.Println("deferred", n) }(x)
+	go func() {}()
+}
+
+// Output:
+// iife hi
+// 42 7 10 7 3 10 mul
+// 3
+// deferred 3
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type T struct{ n int }
+
+func (t T) sum(a int, s string) int { return t.n + a + len(s) }
+
+func pair() (int, string) { return 2, "ab" }
+
+func rev() (string, int) { return "xy", 3 }
+
+func join(a int, s string) string { return fmt.Sprint(a, s) }
+
+func next(a int, s string) (int, string) { return a + 1, s + "!" }
+
+func count(a ...interface{}) int { return len(a) }
+
+func main() {
+	fmt.Println(pair())
+	fmt.Println(join(pair()))
+	fmt.Println(join(next(next(pair()))))
+	fmt.Println(count(pair()))
+	fmt.Println(T{4}.sum(pair()))
+	fmt.Println(strings.Repeat(rev()))
+	f := func(a int, s string) int { return a * len(s) }
+	fmt.Println(f(pair()))
+}
+
+// Output:
+// 2 ab
+// 2ab
+// 4ab!!
+// 2
+// 8
+// xyxyxy
+// 4
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func two() (int, string) { return 2, "two" }
+
+func ints() int { return 1 }
+
+func show(a ...interface{}) {
+	for _, v := range a {
+		switch v := v.(type) {
+		case int:
+			fmt.Println("int", v)
+		case string:
+			fmt.Println("string", v)
+		case error:
+			fmt.Println("error", v)
+		default:
+			fmt.Println("other", v)
+		}
+	}
+}
+
+func pair(a, b interface{}) {
+	fmt.Println(a, b)
+}
+
+func main() {
+	show(ints())
+	show(errors.New("boom"))
+	pair(two())
+	show(fmt.Sprint("x"))
+}
+
+// Output:
+// int 1
+// error boom
+// 2 two
+// string x
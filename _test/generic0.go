@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func Map[T, U any](s []T, f func(T) U) []U {
+	r := make([]U, len(s))
+	for i, v := range s {
+		r[i] = f(v)
+	}
+	return r
+}
+
+func main() {
+	s := Map([]int{1, 2, 3}, func(i int) string { return strconv.Itoa(i * 10) })
+	fmt.Printf("%q\n", s)
+	fmt.Println(Map[string, int](s, func(v string) int { return len(v) }))
+}
+
+// Output:
+// ["10" "20" "30"]
+// [2 2 2]
@@ -0,0 +1,26 @@
+package main
+
+import "fmt"
+
+func Max[T ~int | ~float64 | ~string](vals ...T) T {
+	m := vals[0]
+	for _, v := range vals[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+func main() {
+	i := Max(3, 1, 2)
+	s := Max("a", "b")
+	fmt.Printf("%T %v\n", i, i)
+	fmt.Printf("%T %v\n", s, s)
+	fmt.Println(Max([]float64{1.5, 0.5}...))
+}
+
+// Output:
+// int 3
+// string b
+// 1.5
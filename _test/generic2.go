@@ -0,0 +1,14 @@
+package main
+
+import "fmt"
+
+func Max[T int | string](vals ...T) T {
+	return vals[0]
+}
+
+func main() {
+	fmt.Println(Max())
+}
+
+// Error:
+// 10:14: in call to Max, cannot infer T
@@ -0,0 +1,24 @@
+package main
+
+import "fmt"
+
+type Number interface {
+	int | float64
+}
+
+func Sum[T Number](s []T) T {
+	var r T
+	for _, v := range s {
+		r += v
+	}
+	return r
+}
+
+func main() {
+	fmt.Println(Sum([]int{1, 2, 3}))
+	fmt.Println(Sum([]float64{1.5, 2.25}))
+}
+
+// Output:
+// 6
+// 3.75
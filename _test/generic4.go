@@ -0,0 +1,16 @@
+package main
+
+import "fmt"
+
+type MyInt int
+
+func Twice[T interface{ ~int | ~string }](x T) T {
+	return x + x
+}
+
+func main() {
+	fmt.Println(Twice(2), Twice("ab"), Twice(MyInt(4)))
+}
+
+// Output:
+// 4 abab 8
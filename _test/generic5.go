@@ -0,0 +1,14 @@
+package main
+
+import "fmt"
+
+func Twice[T interface{ ~int | ~string }](x T) T {
+	return x + x
+}
+
+func main() {
+	fmt.Println(Twice(1.5))
+}
+
+// Error:
+// 10:14: float64 does not satisfy interface{~int | ~string} (float64 missing in ~int | ~string)
@@ -0,0 +1,33 @@
+package main
+
+import "fmt"
+
+type Cache[K comparable, V any] struct {
+	m map[K]V
+}
+
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	v, ok := c.m[k]
+	return v, ok
+}
+
+func (c *Cache[K, V]) Set(k K, v V) {
+	if c.m == nil {
+		c.m = map[K]V{}
+	}
+	c.m[k] = v
+}
+
+func main() {
+	c := &Cache[string, int]{}
+	c.Set("a", 1)
+	c.Set("b", 2)
+	fmt.Println(c.Get("a"))
+	fmt.Println(c.Get("c"))
+	fmt.Println(len(c.m))
+}
+
+// Output:
+// 1 true
+// 0 false
+// 2
@@ -0,0 +1,13 @@
+package main
+
+type Cache[K comparable, V any] struct {
+	m map[K]V
+}
+
+func main() {
+	var c Cache[func(), int]
+	_ = c
+}
+
+// Error:
+// 8:14: func() does not satisfy comparable
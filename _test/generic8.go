@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func Map[T, U any](s []T, f func(T) U) []U {
+	r := make([]U, len(s))
+	for i, v := range s {
+		r[i] = f(v)
+	}
+	return r
+}
+
+func main() {
+	var f func([]int, func(int) string) []string
+	f = Map[int, string]
+	g := Map[string, int]
+	fmt.Printf("%T\n", g)
+	s := f([]int{4, 2}, func(i int) string { return strconv.Itoa(i) })
+	fmt.Println(s, g(s, func(v string) int { return len(v) }))
+}
+
+// Output:
+// func([]string, func(string) int) []int
+// [4 2] [1 1]
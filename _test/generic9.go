@@ -0,0 +1,12 @@
+package main
+
+func Double[T any](x T) T {
+	return x + x
+}
+
+func main() {
+	println("ok")
+}
+
+// Error:
+// 4:9: invalid operation: operator + not defined on T
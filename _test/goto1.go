@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var calls int
+
+func fetch() (string, error) {
+	calls++
+	if calls < 3 {
+		return "", errors.New("unavailable")
+	}
+	return "data", nil
+}
+
+func main() {
+	attempts := 0
+	var res string
+retry:
+	attempts++
+	r, err := fetch()
+	if err != nil {
+		fmt.Println("attempt", attempts, "failed:", err)
+		if attempts < 5 {
+			goto retry
+		}
+	}
+	res = r
+	fmt.Println(res, attempts)
+}
+
+// Output:
+// attempt 1 failed: unavailable
+// attempt 2 failed: unavailable
+// data 3
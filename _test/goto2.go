@@ -0,0 +1,17 @@
+package main
+
+import "fmt"
+
+func main() {
+	n := 1
+	if n > 0 {
+		goto done
+	}
+	x := n * 2
+	fmt.Println(x)
+done:
+	fmt.Println("done")
+}
+
+// Error:
+// _test/goto2.go:8:8: goto done jumps over variable declaration at line 10
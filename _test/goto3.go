@@ -0,0 +1,60 @@
+package main
+
+import "fmt"
+
+func main() {
+	// goto out of nested blocks and loops
+	for i := 0; ; i++ {
+		for j := 0; j < 3; j++ {
+			if i*j == 4 {
+				goto out
+			}
+		}
+	}
+out:
+	fmt.Println("out")
+
+	// backward goto over a declaration is allowed
+	n := 0
+again:
+	m := n + 1
+	n = m
+	if n < 3 {
+		goto again
+	}
+	fmt.Println("n", n)
+
+	// goto out of a switch case body
+	switch k := 1; k {
+	case 1:
+		goto two
+	}
+	fmt.Println("not reached")
+two:
+	fmt.Println("two")
+
+	// goto in a closure
+	f := func(x int) (r int) {
+	loop:
+		if x > 0 {
+			r += x
+			x--
+			goto loop
+		}
+		return
+	}
+	fmt.Println(f(4))
+
+	// forward goto in same block, skipping statements only
+	goto end
+	fmt.Println("skipped")
+end:
+	fmt.Println("end")
+}
+
+// Output:
+// out
+// n 3
+// two
+// 10
+// end
@@ -0,0 +1,14 @@
+package main
+
+func main() {
+	if true {
+		goto L
+	}
+	for {
+	L:
+		println("in")
+	}
+}
+
+// Error:
+// 5:8: goto L jumps into block
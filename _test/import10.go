@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+
+	_ "github.com/containous/yaegi/_test/driver"
+	"github.com/containous/yaegi/_test/registry"
+)
+
+func main() {
+	fmt.Println(registry.Drivers())
+	fmt.Println(registry.Lookup("mem"))
+	desc, ok := registry.Lookup("sql")
+	fmt.Printf("%q %v\n", desc, ok)
+}
+
+// Output:
+// [mem null]
+// in memory driver 1.0 true
+// "" false
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Builder interface {
+	With(k, v string) Builder
+	Prefix(p string) Builder
+	Build() string
+}
+
+type builder struct {
+	prefix string
+	parts  []string
+}
+
+func (b *builder) With(k, v string) Builder {
+	b.parts = append(b.parts, k+"="+v)
+	return b
+}
+
+func (b *builder) Prefix(p string) Builder {
+	b.prefix = p
+	return b
+}
+
+func (b *builder) Build() string { return b.prefix + strings.Join(b.parts, ",") }
+
+func New() Builder { return &builder{} }
+
+type Query struct{ clauses []string }
+
+func (q Query) Where(c string) Query {
+	q.clauses = append(q.clauses, c)
+	return q
+}
+
+func (q Query) String() string { return strings.Join(q.clauses, " AND ") }
+
+func main() {
+	s := New().With("a", "1").Prefix("cfg:").With("b", "2").Build()
+	fmt.Println(s)
+	var b Builder = &builder{}
+	b = b.With("x", "y")
+	fmt.Println(b.With("z", "w").Build())
+	q := Query{}.Where("a > 1").Where("b < 2")
+	fmt.Println(q.String())
+}
+
+// Output:
+// cfg:a=1,b=2
+// x=y,z=w
+// a > 1 AND b < 2
@@ -0,0 +1,34 @@
+package main
+
+import "fmt"
+
+type Shape interface {
+	Scale(f int) Shape
+	Area() int
+}
+
+type Rect struct{ W, H int }
+
+func (r Rect) Scale(f int) Shape { return Rect{r.W * f, r.H * f} }
+func (r Rect) Area() int         { return r.W * r.H }
+
+type Square struct{ S int }
+
+func (s Square) Scale(f int) Shape { return Rect{s.S * f, s.S * f} }
+func (s Square) Area() int         { return s.S * s.S }
+
+func main() {
+	shapes := []Shape{Rect{1, 2}, Square{3}}
+	for _, s := range shapes {
+		fmt.Println(s.Scale(2).Scale(3).Area())
+	}
+	var sh Shape = Square{2}
+	sh = sh.Scale(2)
+	r, ok := sh.(Rect)
+	fmt.Println(r, ok, sh.Area())
+}
+
+// Output:
+// 72
+// 324
+// {4 4} true 16
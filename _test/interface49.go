@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+type ReadStringer interface {
+	io.Reader
+	String() string
+}
+
+type T struct {
+	r io.Reader
+}
+
+func (t *T) Read(p []byte) (int, error) { return t.r.Read(p) }
+
+func (t *T) String() string { return "T" }
+
+func show(rs ReadStringer) {
+	b := make([]byte, 3)
+	n, err := rs.Read(b)
+	fmt.Println(rs.String(), string(b[:n]), err)
+}
+
+func main() {
+	var rs ReadStringer = &T{strings.NewReader("hello")}
+	show(rs)
+	show(bytes.NewBufferString("abcdef"))
+
+	b, err := ioutil.ReadAll(rs)
+	fmt.Println(string(b), err)
+
+	var s fmt.Stringer = rs
+	fmt.Println(s.String())
+
+	var i interface{} = strings.NewReader("x")
+	_, ok := i.(ReadStringer)
+	fmt.Println(ok)
+}
+
+// Output:
+// T hel <nil>
+// def abc <nil>
+// lo <nil>
+// T
+// false
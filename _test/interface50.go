@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Wrapper struct {
+	io.Writer
+}
+
+type Counter struct {
+	io.Writer
+	n int
+}
+
+func (c *Counter) Write(p []byte) (int, error) {
+	c.n += len(p)
+	return c.Writer.Write(p)
+}
+
+type Shouter interface{ Shout(string) string }
+
+type upper struct{}
+
+func (upper) Shout(s string) string { return strings.ToUpper(s) }
+
+type Deco struct{ Shouter }
+
+func write(w io.Writer, s string) { w.Write([]byte(s)) }
+
+func main() {
+	var buf bytes.Buffer
+	w := Wrapper{&buf}
+	w.Write([]byte("hello "))
+	write(w, "world")
+	fmt.Fprint(w, "!")
+	fmt.Println(buf.String())
+
+	var out bytes.Buffer
+	c := &Counter{Writer: &out}
+	fmt.Fprint(c, "counted")
+	fmt.Println(out.String(), c.n)
+
+	d := Deco{upper{}}
+	fmt.Println(d.Shout("hi"))
+	var s Shouter = d
+	fmt.Println(s.Shout("ho"))
+}
+
+// Output:
+// hello world!
+// counted 7
+// HI
+// HO
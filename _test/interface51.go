@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+type Reader interface{ Read() string }
+
+type Writer interface{ Write(s string) }
+
+type ReadWriter interface {
+	Reader
+	Writer
+}
+
+type ReadWriteCloser interface {
+	ReadWriter
+	io.Closer
+	Close() error
+}
+
+type buf struct{ data []string }
+
+func (b *buf) Read() string {
+	if len(b.data) == 0 {
+		return ""
+	}
+	s := b.data[0]
+	b.data = b.data[1:]
+	return s
+}
+
+func (b *buf) Write(s string) { b.data = append(b.data, s) }
+
+func (b *buf) Close() error { return nil }
+
+func main() {
+	var rw ReadWriter = &buf{}
+	rw.Write("hello")
+	var r Reader = rw
+	fmt.Println(r.Read())
+
+	var x interface{} = &buf{}
+	if w, ok := x.(Writer); ok {
+		w.Write("a")
+	}
+	if rw2, ok := x.(ReadWriter); ok {
+		fmt.Println(rw2.Read())
+	}
+
+	var c ReadWriteCloser = &buf{}
+	c.Write("b")
+	fmt.Println(c.Read(), c.Close())
+
+	var rd Reader = c
+	_, ok := rd.(Writer)
+	fmt.Println(ok)
+	_, ok = rd.(ReadWriteCloser)
+	fmt.Println(ok)
+	_, ok = x.(ReadWriteCloser)
+	fmt.Println(ok)
+}
+
+// Output:
+// hello
+// a
+// b <nil>
+// true
+// true
+// true
@@ -0,0 +1,18 @@
+package main
+
+type A interface{ F() int }
+
+type B interface{ F() string }
+
+type C interface {
+	A
+	B
+}
+
+func main() {
+	var c C
+	println(c == nil)
+}
+
+// Error:
+// 9:2: duplicate method F
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+type upperReader struct {
+	src string
+	pos int
+}
+
+func (r *upperReader) Read(p []byte) (int, error) {
+	if r.pos >= len(r.src) {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) && n < 3 && r.pos < len(r.src) {
+		p[n] = strings.ToUpper(r.src[r.pos : r.pos+1])[0]
+		n++
+		r.pos++
+	}
+	return n, nil
+}
+
+type countWriter struct {
+	data  []byte
+	calls int
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.calls++
+	w.data = append(w.data, p...)
+	return len(p), nil
+}
+
+func main() {
+	r := &upperReader{src: "hello, world"}
+	w := &countWriter{}
+	n, err := io.Copy(w, r)
+	fmt.Println(n, err, string(w.data), w.calls)
+
+	w2 := &countWriter{}
+	n, err = io.Copy(w2, strings.NewReader("abc"))
+	fmt.Println(n, err, string(w2.data))
+
+	var sb strings.Builder
+	n, err = io.Copy(&sb, &upperReader{src: "xyz"})
+	fmt.Println(n, err, sb.String())
+
+	b, err := ioutil.ReadAll(&upperReader{src: "readall"})
+	fmt.Println(string(b), err)
+}
+
+// Output:
+// 12 <nil> HELLO, WORLD 4
+// 3 <nil> abc
+// 3 <nil> XYZ
+// READALL <nil>
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+type rot13 struct{ r io.Reader }
+
+func (r rot13) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for i := 0; i < n; i++ {
+		c := p[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			p[i] = 'a' + (c-'a'+13)%26
+		case c >= 'A' && c <= 'Z':
+			p[i] = 'A' + (c-'A'+13)%26
+		}
+	}
+	return n, err
+}
+
+type lineCounter struct{ lines int }
+
+func (l *lineCounter) Write(p []byte) (int, error) {
+	l.lines += bytes.Count(p, []byte("\n"))
+	return len(p), nil
+}
+
+func main() {
+	var out bytes.Buffer
+	lc := &lineCounter{}
+	mw := io.MultiWriter(&out, lc)
+	n, err := io.CopyBuffer(mw, rot13{bytes.NewBufferString("Uryyb\nJbeyq\n")}, make([]byte, 4))
+	fmt.Println(n, err, lc.lines)
+	fmt.Print(out.String())
+
+	s := bufio.NewScanner(io.TeeReader(rot13{bytes.NewBufferString("nop\nqrs\n")}, lc))
+	for s.Scan() {
+		fmt.Println(s.Text())
+	}
+	fmt.Println(lc.lines)
+}
+
+// Output:
+// 12 <nil> 2
+// Hello
+// World
+// abc
+// def
+// 4
@@ -0,0 +1,48 @@
+package main
+
+import "fmt"
+
+type Flag uint8
+
+const (
+	FlagA Flag = 1 << iota
+	FlagB
+	FlagC
+	FlagD
+)
+
+const (
+	_  = iota
+	KA = 1 << (iota * 2)
+	KB
+	KC
+)
+
+func (f Flag) Has(g Flag) bool { return f&g != 0 }
+
+func main() {
+	const (
+		read = 1 << iota
+		write
+		exec
+	)
+	perm := read | exec
+	fmt.Println(perm&write != 0, perm&exec != 0, perm)
+
+	fmt.Println(FlagA, FlagB, FlagC, FlagD, KA, KB, KC)
+	flags := FlagA | FlagC
+	fmt.Println(flags, flags&FlagB != 0, flags&FlagC != 0)
+	fmt.Println(flags.Has(FlagA), flags.Has(FlagD))
+	flags |= FlagD
+	flags &^= FlagA
+	fmt.Println(flags, flags.Has(FlagA), flags.Has(FlagD))
+	fmt.Printf("%08b\n", flags)
+}
+
+// Output:
+// false true 5
+// 1 2 4 8 4 16 64
+// 5 false true
+// true false
+// 12 false true
+// 00001100
@@ -0,0 +1,48 @@
+package main
+
+import "fmt"
+
+type ByteSize float64
+
+const (
+	_           = iota // ignore first value by assigning to blank identifier
+	KB ByteSize = 1 << (10 * iota)
+	MB
+	GB
+	TB
+	PB
+	EB
+	ZB
+	YB
+)
+
+const (
+	_  = iota
+	Ki = 1 << (10 * iota)
+	Mi
+	Gi
+	Ti
+	Pi
+	Ei
+	Zi
+	Yi
+)
+
+func main() {
+	fmt.Println(KB, MB, GB, TB)
+	fmt.Println(PB, EB, ZB, YB)
+	fmt.Println(Ki, Mi, Gi, Ti, Pi, Ei)
+	fmt.Println(Yi/Zi, Zi/Ei, float64(Yi))
+
+	var i int64 = Ei
+	var u uint32 = Gi*4 - 1
+	var b ByteSize = 1536
+	fmt.Println(i, u, b/KB, MB/KB)
+}
+
+// Output:
+// 1024 1.048576e+06 1.073741824e+09 1.099511627776e+12
+// 1.125899906842624e+15 1.152921504606847e+18 1.1805916207174113e+21 1.2089258196146292e+24
+// 1024 1048576 1073741824 1099511627776 1125899906842624 1152921504606846976
+// 1024 1024 1.2089258196146292e+24
+// 1152921504606846976 4294967295 1.5 1024
@@ -0,0 +1,48 @@
+package main
+
+import "fmt"
+
+type Flag uint8
+
+const (
+	Read Flag = 1 << iota
+	Write
+	_
+	Exec
+	All = Read | Write | Exec
+)
+
+func (f Flag) Has(g Flag) bool { return f&g != 0 }
+
+const (
+	KB = 1 << (10 * (iota + 1))
+	MB
+	GB
+)
+
+const (
+	a, b = iota, iota * 10
+	c, d
+	_, _
+	e, f
+)
+
+func main() {
+	fmt.Println(Read, Write, Exec, All)
+	fmt.Println(All.Has(Exec), Write.Has(Read), Exec.Has(Exec))
+	fmt.Println(KB, MB, GB)
+	fmt.Println(a, b, c, d, e, f)
+
+	const (
+		x, y = iota + 1, -iota
+		z, t
+	)
+	fmt.Println(x, y, z, t)
+}
+
+// Output:
+// 1 2 8 11
+// true false true
+// 1024 1048576 1073741824
+// 0 0 1 10 3 30
+// 1 0 2 -1
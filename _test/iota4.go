@@ -0,0 +1,42 @@
+package main
+
+import "fmt"
+
+type Weekday int
+
+const (
+	Sunday Weekday = iota
+	Monday
+	Tuesday
+)
+
+func (d Weekday) String() string { return [...]string{"Sun", "Mon", "Tue"}[d] }
+
+type Size float64
+
+const (
+	Small Size = iota * 0.5
+	Medium
+	Large
+)
+
+const (
+	c0 = iota * 2
+	c1 = "s"
+	c2
+	c3 = iota + 0.5
+	c4
+)
+
+func main() {
+	fmt.Println(Sunday, Monday, Tuesday)
+	fmt.Println(Tuesday.String(), Monday+1)
+	fmt.Println(Small, Medium, Large, Large*3)
+	fmt.Println(c0, c1, c2, c3, c4)
+}
+
+// Output:
+// Sun Mon Tue
+// Tue Tue
+// 0 0.5 1 3
+// 0 s s 3.5 4.5
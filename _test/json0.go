@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type Point struct{ X, Y int }
+
+func (p Point) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("\"%d,%d\"", p.X, p.Y)), nil
+}
+
+func (p *Point) UnmarshalJSON(b []byte) error {
+	_, err := fmt.Sscanf(string(b), "\"%d,%d\"", &p.X, &p.Y)
+	return err
+}
+
+func main() {
+	p := Point{1, 2}
+	b, err := json.Marshal(p)
+	fmt.Println(string(b), err)
+
+	b, err = json.MarshalIndent(p, "", " ")
+	fmt.Println(string(b), err)
+
+	err = json.NewEncoder(os.Stdout).Encode(p)
+	fmt.Println(err)
+
+	var q Point
+	err = json.Unmarshal([]byte(`"4,5"`), &q)
+	fmt.Println(q, err)
+
+	var r Point
+	err = json.NewDecoder(strings.NewReader(`"6,7"`)).Decode(&r)
+	fmt.Println(r, err)
+}
+
+// Output:
+// "1,2" <nil>
+// "1,2" <nil>
+// "1,2"
+// <nil>
+// {4 5} <nil>
+// {6 7} <nil>
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type T struct {
+	Name  string `json:"name"`
+	Age   int    `json:"age,omitempty"`
+	Inner struct {
+		X int `json:"x"`
+	} `json:"inner"`
+}
+
+func main() {
+	b, err := json.Marshal(T{Name: "foo"})
+	fmt.Println(string(b), err)
+
+	var t T
+	err = json.Unmarshal([]byte(`{"name":"bar","age":3,"inner":{"x":2}}`), &t)
+	fmt.Println(t.Name, t.Age, t.Inner.X, err)
+}
+
+// Output:
+// {"name":"foo","inner":{"x":0}} <nil>
+// bar 3 2 <nil>
@@ -0,0 +1,41 @@
+package main
+
+import "fmt"
+
+type Point struct{ X, Y int }
+
+func main() {
+	m := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}
+	for k := range m {
+		delete(m, k)
+	}
+	fmt.Println(len(m), m)
+
+	m2 := map[int]int{}
+	for i := 0; i < 100; i++ {
+		m2[i] = i
+	}
+	deleted := map[int]bool{}
+	for k := range m2 {
+		if deleted[k] {
+			fmt.Println("deleted entry produced:", k)
+		}
+		deleted[k^1] = true
+		delete(m2, k^1)
+		delete(m2, k)
+	}
+	fmt.Println(len(m2))
+
+	m3 := map[Point]interface{}{{1, 2}: "a", {3, 4}: 5}
+	for k, v := range m3 {
+		if v != nil {
+			delete(m3, k)
+		}
+	}
+	fmt.Println(len(m3))
+}
+
+// Output:
+// 0 map[]
+// 0
+// 0
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Key struct {
+	Name string
+	ID   int
+}
+
+func keys(m map[Key]string) []Key {
+	res := make([]Key, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+func main() {
+	m := map[Key]string{{"a", 3}: "x", {"b", 1}: "y", {"c", 2}: "z"}
+	for _, k := range keys(m) {
+		fmt.Println(k, m[k])
+	}
+}
+
+// Output:
+// {b 1} y
+// {c 2} z
+// {a 3} x
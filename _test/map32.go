@@ -0,0 +1,27 @@
+package main
+
+import "fmt"
+
+type Point struct {
+	X, Y int
+}
+
+func main() {
+	set := map[Point]struct{}{}
+	counts := map[Point]int{}
+	for _, p := range []Point{{1, 2}, {3, 4}, {1, 2}, {0, 0}, {3, 4}, {1, 2}} {
+		set[p] = struct{}{}
+		counts[p]++
+	}
+	counts[Point{0, 0}] += 10
+	fmt.Println(len(set), len(counts))
+	fmt.Println(counts[Point{1, 2}], counts[Point{3, 4}], counts[Point{0, 0}])
+
+	_, ok := set[Point{3, 4}]
+	fmt.Println(ok)
+}
+
+// Output:
+// 3 3
+// 3 2 11
+// true
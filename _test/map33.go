@@ -0,0 +1,41 @@
+package main
+
+import "fmt"
+
+func main() {
+	m := map[string]int{"a": 1}
+	for _, k := range []string{"a", "b"} {
+		if v, ok := m[k]; ok {
+			fmt.Println("found", k, v)
+		} else {
+			fmt.Println("missing", k, v, ok)
+		}
+	}
+
+	mi := map[string]interface{}{"a": 2}
+	for _, k := range []string{"a", "b"} {
+		if v, ok := mi[k]; ok {
+			fmt.Println("found", k, v)
+		} else {
+			fmt.Println("missing", k, v, ok)
+		}
+	}
+
+	v, ok := 10, "outer"
+	if v, ok := m["a"]; ok {
+		fmt.Println(v)
+	}
+	if _, ok := m["b"]; !ok {
+		fmt.Println("no b")
+	}
+	fmt.Println(v, ok)
+}
+
+// Output:
+// found a 1
+// missing b 0 false
+// found a 2
+// missing b <nil> false
+// 1
+// no b
+// 10 outer
@@ -0,0 +1,31 @@
+package main
+
+import "fmt"
+
+type Getter interface{ Get() int }
+
+type GetSetter interface {
+	Get() int
+	Set(int)
+}
+
+type T struct{ v int }
+
+func (t T) Get() int { return t.v }
+
+func (t *T) Set(v int) { t.v = v }
+
+type U struct{ T }
+
+func main() {
+	var g Getter = T{1}
+	var gs GetSetter = &T{2}
+	gs.Set(3)
+	var g2 Getter = &T{4}
+	var gs2 GetSetter = &U{T{5}}
+	gs2.Set(6)
+	fmt.Println(g.Get(), gs.Get(), g2.Get(), gs2.Get())
+}
+
+// Output:
+// 1 3 4 6
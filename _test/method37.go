@@ -0,0 +1,20 @@
+package main
+
+type GetSetter interface {
+	Get() int
+	Set(int)
+}
+
+type T struct{ v int }
+
+func (t T) Get() int { return t.v }
+
+func (t *T) Set(v int) { t.v = v }
+
+func main() {
+	var gs GetSetter = T{1}
+	println(gs.Get())
+}
+
+// Error:
+// 15:21: cannot use type main.T as type main.GetSetter in assignment
@@ -0,0 +1,29 @@
+package main
+
+import "fmt"
+
+type T struct{ name string }
+
+func (t T) Hello() string   { return "hello " + t.name }
+func (t *T) PHello() string { return "phello " + t.name }
+
+func main() {
+	t := T{"a"}
+	f := t.Hello
+	g := t.PHello
+	fs := []func() string{t.Hello}
+	t = T{"b"}
+	fmt.Println(f(), g(), fs[0]())
+	p := &T{"c"}
+	h := p.Hello
+	k := p.PHello
+	p.name = "d"
+	fmt.Println(h(), k())
+	p = &T{"e"}
+	fmt.Println(h(), k())
+}
+
+// Output:
+// hello a phello b hello a
+// hello c phello d
+// hello c phello d
@@ -0,0 +1,76 @@
+package main
+
+import "fmt"
+
+type T struct{ n int }
+
+func (t T) Get() int      { return t.n }
+func (t *T) Inc(d int)    { t.n += d }
+func (t T) Add(d int) int { return t.n + d }
+
+type E struct {
+	T
+	name string
+}
+
+type P struct {
+	*T
+}
+
+func main() {
+	t := T{1}
+	get := t.Get
+	inc := t.Inc
+	inc(2)
+	fmt.Println(get(), t.Get(), t.n)
+
+	pt := &T{5}
+	pget := pt.Get
+	pinc := pt.Inc
+	pinc(1)
+	fmt.Println(pget(), pt.n)
+
+	// method expressions
+	fget := T.Get
+	fadd := T.Add
+	finc := (*T).Inc
+	fpget := (*T).Get
+	finc(pt, 10)
+	fmt.Println(fget(t), fadd(t, 4), fpget(pt))
+
+	// promoted methods
+	e := E{T{7}, "e"}
+	eget := e.Get
+	einc := e.Inc
+	einc(1)
+	fmt.Println(eget(), e.Get(), e.n)
+	feget := E.Get
+	feinc := (*E).Inc
+	feinc(&e, 2)
+	fmt.Println(feget(e))
+
+	p := P{&T{3}}
+	pinc2 := p.Inc
+	pinc2(4)
+	fmt.Println(p.Get(), P.Get(p))
+
+	// method values in slices and passed as func
+	fs := []func() int{t.Get, pt.Get, e.Get}
+	for _, f := range fs {
+		fmt.Print(f(), ";")
+	}
+	fmt.Println()
+	apply := func(f func(int), v int) { f(v) }
+	apply(pt.Inc, 100)
+	fmt.Println(pt.n)
+}
+
+// Output:
+// 1 3 3
+// 5 6
+// 3 7 16
+// 7 8 8
+// 10
+// 7 7
+// 3;16;10;
+// 116
@@ -0,0 +1,55 @@
+package main
+
+import "fmt"
+
+func helper() interface{} { return recover() }
+
+func indirect() {
+	defer func() {
+		fmt.Println("direct:", recover())
+	}()
+	defer func() {
+		r := helper()
+		fmt.Println("indirect:", r)
+	}()
+	panic("p1")
+}
+
+func nested() {
+	defer func() {
+		fmt.Println("outer:", recover())
+	}()
+	defer func() {
+		defer func() {
+			fmt.Println("inner:", recover())
+		}()
+		panic("p3")
+	}()
+	panic("p2")
+}
+
+func repanic() {
+	defer func() {
+		r := recover()
+		fmt.Println("repanic:", r)
+		panic(fmt.Sprint("again ", r))
+	}()
+	panic("p4")
+}
+
+func main() {
+	indirect()
+	nested()
+	defer func() {
+		fmt.Println("main:", recover())
+	}()
+	repanic()
+}
+
+// Output:
+// indirect: <nil>
+// direct: p1
+// inner: p3
+// outer: p2
+// repanic: p4
+// main: again p4
@@ -0,0 +1,23 @@
+package main
+
+import "fmt"
+
+func try(v interface{}) {
+	defer func() {
+		r := recover()
+		fmt.Println(r != nil, r)
+	}()
+	panic(v)
+}
+
+func main() {
+	try(nil)
+	try("str")
+	var err error
+	try(err)
+}
+
+// Output:
+// true panic called with nil argument (use -d=panicnil=1 to disable)
+// true str
+// true panic called with nil argument (use -d=panicnil=1 to disable)
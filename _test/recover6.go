@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func inner() {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println("inner observed:", r)
+			panic(r)
+		}
+	}()
+	panic("boom")
+}
+
+func middle() {
+	defer fmt.Println("middle deferred")
+	inner()
+	fmt.Println("not reached")
+}
+
+func outer() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("outer recovered: %v", r)
+		}
+	}()
+	middle()
+	return nil
+}
+
+func wrapErr() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+	func() {
+		defer func() {
+			r := recover()
+			panic(fmt.Errorf("wrapped: %w", r.(error)))
+		}()
+		panic(errors.New("base"))
+	}()
+	return nil
+}
+
+func main() {
+	fmt.Println(outer())
+	err := wrapErr()
+	fmt.Println(err, errors.Unwrap(err))
+}
+
+// Output:
+// inner observed: boom
+// middle deferred
+// outer recovered: boom
+// wrapped: base base
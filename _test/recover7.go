@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+type bailout struct {
+	depth int
+	err   error
+}
+
+var unwound []int
+
+func descend(depth, max int) int {
+	defer func() { unwound = append(unwound, depth) }()
+	if depth == max {
+		panic(bailout{depth, errors.New("too deep")})
+	}
+	return descend(depth+1, max) + 1
+}
+
+func parse(max int) (res int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			b, ok := r.(bailout)
+			if !ok {
+				panic(r)
+			}
+			err = fmt.Errorf("bailout at depth %d: %v", b.depth, b.err)
+		}
+	}()
+	return descend(0, max), nil
+}
+
+func main() {
+	res, err := parse(5)
+	fmt.Println(res, err)
+	fmt.Println(unwound)
+}
+
+// Output:
+// 0 bailout at depth 5: too deep
+// [5 4 3 2 1 0]
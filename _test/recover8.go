@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+type handler func(string) string
+
+func middleware(next handler) handler {
+	return func(s string) (r string) {
+		defer func() {
+			if e := recover(); e != nil {
+				stack := string(debug.Stack())
+				fmt.Println("recovered:", e)
+				fmt.Println(strings.Contains(stack, "main.index("), strings.Contains(stack, "recover8.go:31"))
+				fmt.Println(strings.Contains(stack, "main.middleware.func1("), strings.Contains(stack, "recover8.go:24"))
+				fmt.Println(strings.Contains(stack, "main.serve("), strings.Contains(stack, "main.main("))
+				r = "error"
+			}
+		}()
+		fmt.Printf("serving %q\n", s)
+		return next(s)
+	}
+}
+
+func index(s string) string {
+	fmt.Printf("index %q\n", s)
+	if s == "" {
+		panic("empty path")
+	}
+	return "ok"
+}
+
+func serve(h handler, s string) string { return h(s) }
+
+func main() {
+	h := middleware(index)
+	fmt.Println(serve(h, "/"))
+	fmt.Println(serve(h, ""))
+}
+
+// Output:
+// serving "/"
+// index "/"
+// ok
+// serving ""
+// index ""
+// recovered: empty path
+// true true
+// true true
+// true true
+// error
@@ -0,0 +1,66 @@
+package main
+
+import "fmt"
+
+func handlePanic() {
+	fmt.Println("handlePanic recover:", recover())
+}
+
+func helper() {
+	fmt.Println("helper recover:", recover())
+}
+
+func makeHandler(name string) func() {
+	return func() { fmt.Println(name, "recover:", recover()) }
+}
+
+type T struct{ name string }
+
+func (t T) handle() { fmt.Println(t.name, "recover:", recover()) }
+
+func run(name string, f func()) {
+	defer func() { fmt.Println(name, "outer recover:", recover()) }()
+	f()
+}
+
+func main() {
+	run("direct", func() {
+		defer handlePanic()
+		panic("direct")
+	})
+	run("literal", func() {
+		defer func() { fmt.Println("literal recover:", recover()) }()
+		panic("literal")
+	})
+	run("returned", func() {
+		defer makeHandler("returned")()
+		panic("returned")
+	})
+	run("method", func() {
+		defer T{"method"}.handle()
+		panic("method")
+	})
+	run("indirect", func() {
+		defer func() { helper() }()
+		panic("indirect")
+	})
+	run("closure", func() {
+		h := func() { fmt.Println("closure recover:", recover()) }
+		defer func() { h() }()
+		panic("closure")
+	})
+}
+
+// Output:
+// handlePanic recover: direct
+// direct outer recover: <nil>
+// literal recover: literal
+// literal outer recover: <nil>
+// returned recover: returned
+// returned outer recover: <nil>
+// method recover: method
+// method outer recover: <nil>
+// helper recover: <nil>
+// indirect outer recover: indirect
+// closure recover: <nil>
+// closure outer recover: closure
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+type P struct {
+	X, Y int
+}
+
+type T struct {
+	Name  string
+	Tags  []string
+	M     map[string]int
+	P     *P
+	Inner P
+	Any   interface{}
+}
+
+func main() {
+	a := T{Name: "a", Tags: []string{"x"}, M: map[string]int{"k": 1}, P: &P{1, 2}, Inner: P{3, 4}, Any: P{5, 6}}
+	b := T{Name: "a", Tags: []string{"x"}, M: map[string]int{"k": 1}, P: &P{1, 2}, Inner: P{3, 4}, Any: P{5, 6}}
+	fmt.Println(reflect.DeepEqual(a, b))
+	b.Tags[0] = "y"
+	fmt.Println(reflect.DeepEqual(a, b))
+	b.Tags[0] = "x"
+	b.P.X = 9
+	fmt.Println(reflect.DeepEqual(a, b))
+
+	fmt.Println(reflect.DeepEqual([]P{{1, 2}}, []P{{1, 2}}))
+	fmt.Println(reflect.DeepEqual([]interface{}{1, "a", P{1, 1}}, []interface{}{1, "a", P{1, 1}}))
+	fmt.Println(reflect.DeepEqual([]interface{}{1, "a", P{1, 1}}, []interface{}{1, "a", P{1, 2}}))
+
+	m1 := map[string]interface{}{"a": P{1, 1}, "b": []interface{}{1, nil}}
+	m2 := map[string]interface{}{"a": P{1, 1}, "b": []interface{}{1, nil}}
+	fmt.Println(reflect.DeepEqual(m1, m2))
+	m2["a"] = P{2, 2}
+	fmt.Println(reflect.DeepEqual(m1, m2))
+
+	j, _ := json.Marshal(m1)
+	fmt.Println(string(j))
+}
+
+// Output:
+// true
+// false
+// false
+// true
+// true
+// false
+// true
+// false
+// {"a":{"X":1,"Y":1},"b":[1,null]}
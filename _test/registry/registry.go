@@ -0,0 +1,24 @@
+package registry
+
+import "sort"
+
+var drivers = map[string]string{}
+
+// Register records a driver by name, as done in driver package init functions.
+func Register(name, desc string) { drivers[name] = desc }
+
+// Drivers returns the sorted names of registered drivers.
+func Drivers() []string {
+	names := []string{}
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the description of a registered driver.
+func Lookup(name string) (string, bool) {
+	desc, ok := drivers[name]
+	return desc, ok
+}
@@ -0,0 +1,16 @@
+package main
+
+import "fmt"
+
+const base = 40
+
+func answer() int { return base + 2 }
+
+func ratio() float64 { return 1 / 4.0 }
+
+func main() {
+	fmt.Println(answer(), ratio())
+}
+
+// Output:
+// 42 0.25
@@ -0,0 +1,36 @@
+package main
+
+import "fmt"
+
+func main() {
+	ch := make(chan int, 1)
+	for i := 0; i < 3; i++ {
+		ch <- i
+		select {
+		case v := <-ch:
+			if v == 1 {
+				break
+			}
+			fmt.Println("v", v)
+		}
+	}
+
+	n := 0
+loop:
+	for {
+		ch <- n
+		select {
+		case v := <-ch:
+			if v == 2 {
+				break loop
+			}
+			n++
+		}
+	}
+	fmt.Println("n", n)
+}
+
+// Output:
+// v 0
+// v 2
+// n 2
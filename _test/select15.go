@@ -0,0 +1,43 @@
+package main
+
+import "fmt"
+
+type event struct {
+	id   int
+	name string
+}
+
+func loop(events <-chan event, done <-chan struct{}, out chan<- string) {
+	count := 0
+	for {
+		select {
+		case e := <-events:
+			count++
+			out <- fmt.Sprintf("event %d: %s", e.id, e.name)
+		case <-done:
+			out <- fmt.Sprintf("done after %d events", count)
+			close(out)
+			return
+		}
+	}
+}
+
+func main() {
+	events := make(chan event)
+	done := make(chan struct{})
+	out := make(chan string, 10)
+	go loop(events, done, out)
+	for i, n := range []string{"start", "tick", "stop"} {
+		events <- event{i, n}
+	}
+	close(done)
+	for s := range out {
+		fmt.Println(s)
+	}
+}
+
+// Output:
+// event 0: start
+// event 1: tick
+// event 2: stop
+// done after 3 events
@@ -0,0 +1,35 @@
+package main
+
+import "fmt"
+
+func main() {
+	c := make(chan int, 1)
+	c <- 1
+	select {
+	case <-c:
+	}
+	fmt.Println("recv")
+
+	select {
+	case c <- 2:
+	}
+	fmt.Println("send")
+
+	var v int
+	select {
+	case v = <-c:
+	}
+	fmt.Println(v)
+
+	c <- 3
+	select {
+	case v, ok := <-c:
+		fmt.Println(v, ok)
+	}
+}
+
+// Output:
+// recv
+// send
+// 2
+// 3 true
@@ -0,0 +1,81 @@
+package main
+
+import "fmt"
+
+var calls int
+
+func next(v int) int {
+	calls++
+	return v
+}
+
+func main() {
+	c := make(chan int)
+	select {
+	case c <- next(1):
+		fmt.Println("sent")
+	default:
+		fmt.Println("default")
+	}
+	fmt.Println("calls", calls)
+
+	b := make(chan int, 1)
+	select {
+	case b <- next(2):
+		fmt.Println("sent", <-b)
+	default:
+		fmt.Println("default")
+	}
+	fmt.Println("calls", calls)
+
+	in, out := make(chan int), make(chan int)
+	done := make(chan bool)
+	go func() {
+		for v := range out {
+			fmt.Println("got", v)
+		}
+		done <- true
+	}()
+	go func() {
+		for i := 0; i < 3; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+	var pending []int
+	for in != nil || len(pending) > 0 {
+		var send chan int
+		var first int
+		if len(pending) > 0 {
+			send, first = out, pending[0]
+		}
+		select {
+		case v, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			pending = append(pending, v*10)
+		case send <- first:
+			pending = pending[1:]
+		}
+	}
+	close(out)
+	<-done
+	s := struct{ n int }{5}
+	sc := make(chan int, 1)
+	select {
+	case sc <- s.n + 1:
+	}
+	fmt.Println(<-sc)
+}
+
+// Output:
+// default
+// calls 1
+// sent 2
+// calls 2
+// got 0
+// got 10
+// got 20
+// 6
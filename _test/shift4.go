@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+func main() {
+	var u8 uint8 = 0xff
+	var n uint = 8
+	fmt.Println(u8<<n, u8>>n, u8<<7)
+
+	var u uint64 = 1
+	var big uint = 64
+	fmt.Println(u<<big, u<<(big-1), u>>big)
+
+	var i int64 = -8
+	fmt.Println(i>>1, i>>63, i>>big, i<<big)
+
+	var i8 int8 = -128
+	fmt.Println(i8>>3, i8>>10, i8<<1)
+
+	var s int = 3
+	var s8 int8 = 2
+	fmt.Println(1<<s, u8>>s, i>>s, i<<s8, u<<s8)
+
+	x := 1
+	x <<= s
+	x >>= 1
+	fmt.Println(x)
+
+	var v uint64 = 1 << (big + 6)
+	var w int32 = 1 << (big - 34)
+	fmt.Println(v, w)
+
+	const c = 1 << 64 >> 60
+	fmt.Println(c)
+
+	defer func() { fmt.Println("recovered:", recover()) }()
+	neg := -1
+	fmt.Println(1 << neg)
+}
+
+// Output:
+// 0 0 128
+// 0 9223372036854775808 0
+// -4 -1 -1 0
+// -16 -1 0
+// 8 31 -1 -32 4
+// 4
+// 0 1073741824
+// 16
+// recovered: runtime error: negative shift amount
@@ -0,0 +1,20 @@
+package main
+
+import "fmt"
+
+func main() {
+	s := []byte{1, 2, 3, 4, 5}
+	p := (*[4]byte)(s)
+	p[0] = 9
+	fmt.Println(s, *p, len(p))
+	a := [3]byte(s)
+	a[1] = 7
+	fmt.Println(s, a)
+	defer func() { fmt.Println("recovered:", recover()) }()
+	_ = (*[8]byte)(s)
+}
+
+// Output:
+// [9 2 3 4 5] [9 2 3 4] 4
+// [9 2 3 4 5] [9 7 3]
+// recovered: runtime error: cannot convert slice with length 5 to array or pointer to array with length 8
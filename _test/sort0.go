@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Person struct {
+	Name string
+	Age  int
+}
+
+func main() {
+	people := []Person{{"Alice", 32}, {"Bob", 25}, {"Carol", 41}, {"Dave", 25}}
+	calls := 0
+	sort.SliceStable(people, func(i, j int) bool {
+		calls++
+		return people[i].Age < people[j].Age
+	})
+	fmt.Println(people, calls > 0)
+
+	sort.Slice(people, func(i, j int) bool { return people[i].Name > people[j].Name })
+	fmt.Println(people)
+}
+
+// Output:
+// [{Bob 25} {Dave 25} {Alice 32} {Carol 41}] true
+// [{Dave 25} {Carol 41} {Bob 25} {Alice 32}]
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Name string
+
+type Names []string
+
+type Num int
+
+func (n Name) Upper() Name { return Name(strings.ToUpper(string(n))) }
+
+func main() {
+	n := Name("bob")
+	fmt.Println(strings.ToUpper(string(n)), n.Upper(), strings.Repeat(string(n), 2))
+	fmt.Println(string(n)+"!", strings.Contains(string(n), "o"))
+
+	var k Num = 42
+	fmt.Println(strconv.Itoa(int(k)))
+	v, _ := strconv.Atoi("12")
+	k = Num(v)
+	fmt.Println(k + 1)
+
+	m := Name(strings.TrimSpace("  x  "))
+	fmt.Println(m, m == "x")
+
+	ns := Names{"b", "a"}
+	sort.Strings(ns)
+	fmt.Println(ns)
+}
+
+// Output:
+// BOB BOB bobbob
+// bob! true
+// 42
+// 13
+// x true
+// [a b]
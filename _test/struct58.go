@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Inner struct{ X, Y int }
+
+func (i Inner) Sum() int { return i.X + i.Y }
+
+type Base struct{ Name string }
+
+type Outer struct {
+	Inner
+	*Base
+	Z int
+}
+
+type Deep struct {
+	Outer
+	W int
+}
+
+type Counter struct {
+	sync.Mutex
+	time.Duration
+	n int
+}
+
+func main() {
+	o := Outer{Inner: Inner{X: 1, Y: 2}, Base: &Base{"b"}, Z: 3}
+	fmt.Println(o.X, o.Y, o.Sum(), o.Name, o.Z, o.Inner)
+
+	p := &Outer{Inner: Inner{4, 5}}
+	fmt.Println(p.X, p.Sum(), p.Base == nil)
+
+	d := Deep{Outer: Outer{Inner: Inner{X: 9}}, W: 1}
+	fmt.Println(d.X, d.Inner.X, d.Outer.Inner.Y, d.Sum())
+
+	ds := []Deep{{Outer: Outer{Z: 2}}, {W: 3}}
+	m := map[string]Outer{"a": {Inner: Inner{X: 10}}}
+	fmt.Println(ds[0].Z, ds[1].W, m["a"].X)
+
+	c := Counter{Duration: time.Second, n: 1}
+	c.Lock()
+	c.n++
+	c.Unlock()
+	fmt.Println(c.Duration, c.n, c.Seconds())
+}
+
+// Output:
+// 1 2 3 b 3 {1 2}
+// 4 9 true
+// 9 9 0 9
+// 2 3 10
+// 1s 2 1
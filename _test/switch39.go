@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Shape interface{ Area() float64 }
+
+type Rect struct{ W, H float64 }
+
+func (r Rect) Area() float64 { return r.W * r.H }
+func (r Rect) Perimeter() float64 { return 2 * (r.W + r.H) }
+
+type Circle struct{ R float64 }
+
+func (c *Circle) Area() float64 { return 3 * c.R * c.R }
+func (c *Circle) Diameter() float64 { return 2 * c.R }
+
+func describe(x interface{}) string {
+	switch v := x.(type) {
+	case io.Reader:
+		b := make([]byte, 5)
+		n, _ := v.Read(b)
+		return "reader " + string(b[:n])
+	case Rect:
+		return fmt.Sprint("rect ", v.Perimeter(), v.Area())
+	case *Circle:
+		return fmt.Sprint("circle ", v.Diameter(), v.Area())
+	case Shape:
+		return fmt.Sprint("shape ", v.Area())
+	case fmt.Stringer:
+		return "stringer " + v.String()
+	case error:
+		return "error " + v.Error()
+	case nil:
+		return "nil"
+	default:
+		return fmt.Sprint("other ", v)
+	}
+}
+
+type S string
+
+func (s S) String() string { return strings.ToUpper(string(s)) }
+
+func main() {
+	fmt.Println(describe(strings.NewReader("hello world")))
+	fmt.Println(describe(Rect{2, 3}))
+	fmt.Println(describe(&Circle{1}))
+	fmt.Println(describe(&Rect{1, 1}))
+	fmt.Println(describe(S("abc")))
+	fmt.Println(describe(fmt.Errorf("boom")))
+	fmt.Println(describe(nil))
+	fmt.Println(describe(42))
+	var sh Shape = Rect{1, 2}
+	switch v := sh.(type) {
+	case Rect:
+		fmt.Println(v.Perimeter())
+	}
+}
+
+// Output:
+// reader hello
+// rect 10 6
+// circle 2 3
+// shape 1
+// stringer ABC
+// error boom
+// nil
+// other 42
+// 6
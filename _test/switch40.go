@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type T struct{}
+
+func (T) Read(b []byte) (int, error) { return 0, io.EOF }
+
+func kind(x interface{}) string {
+	switch x.(type) {
+	case error:
+		return "error"
+	case io.Reader, io.Writer:
+		return "io"
+	case int, string:
+		return "basic"
+	}
+	return "unknown"
+}
+
+func main() {
+	fmt.Println(kind(errors.New("e")), kind(os.Stdout), kind(T{}), kind(strings.NewReader("")), kind(1), kind(1.5))
+
+	var err error = &os.PathError{Op: "open", Path: "x", Err: os.ErrNotExist}
+	switch e := err.(type) {
+	case *os.LinkError:
+		fmt.Println("link", e.Op)
+	case *os.PathError:
+		fmt.Println("path", e.Op, e.Path)
+	}
+}
+
+// Output:
+// error io io io basic unknown
+// path open x
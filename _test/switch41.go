@@ -0,0 +1,20 @@
+package main
+
+import "fmt"
+
+const Two = 2
+
+func main() {
+	x := 2
+	switch x {
+	case 1, Two:
+		fmt.Println("one or two")
+	case 3:
+		fmt.Println("three")
+	case 1 + 1:
+		fmt.Println("two again")
+	}
+}
+
+// Error:
+// 14:7: duplicate case 2 in switch
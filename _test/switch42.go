@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type T struct{}
+
+func (t T) String() string { return "T" }
+
+func f(x interface{}) {
+	switch v := x.(type) {
+	case int, int64:
+		fmt.Printf("int or int64: %v %T\n", v, v)
+	case string:
+		fmt.Println("string", len(v))
+	case fmt.Stringer, io.Reader:
+		fmt.Println("stringer or reader", v != nil)
+	case nil, bool:
+		fmt.Println("nil or bool", v)
+	case int8, int16:
+		v = "replaced"
+		fmt.Println(v)
+	default:
+		fmt.Println("default", v)
+	}
+}
+
+func main() {
+	f(1)
+	f(int64(2))
+	f("abc")
+	f(T{})
+	f(strings.NewReader("x"))
+	f(nil)
+	f(true)
+	f(int8(3))
+	f(int16(4))
+	f(3.5)
+	f(uint(5))
+}
+
+// Output:
+// int or int64: 1 int
+// int or int64: 2 int64
+// string 3
+// stringer or reader true
+// stringer or reader true
+// nil or bool <nil>
+// nil or bool true
+// replaced
+// replaced
+// default 3.5
+// default 5
@@ -0,0 +1,12 @@
+package main
+
+func main() {
+	var x interface{} = 1
+	switch v := x.(type) {
+	case int, int64:
+		println(v + 1)
+	}
+}
+
+// Error:
+// 7:11: invalid operation: operator + not defined on interface{}
@@ -0,0 +1,23 @@
+package main
+
+import "fmt"
+
+func main() {
+	x, b := 2, true
+	switch {
+	case x > 1, true:
+		fmt.Println("first")
+	case true:
+		fmt.Println("second")
+	}
+	switch b {
+	case true:
+		fmt.Println("true")
+	case true:
+		fmt.Println("true again")
+	}
+}
+
+// Output:
+// first
+// true
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	timeout               = 5 * time.Second
+	half                  = timeout / 2
+	long    time.Duration = 1e9
+)
+
+func double(d time.Duration) time.Duration { return d * 2 }
+
+func main() {
+	n := 3
+	d := time.Duration(n) * time.Millisecond
+	fmt.Println(timeout, half, long, d, timeout > d, d.String())
+	fmt.Printf("%v %s %d\n", timeout, d, d)
+
+	f := timeout + 1500*time.Millisecond
+	fmt.Println(f, f.Round(time.Second), f.Truncate(time.Second), f.Seconds())
+	fmt.Println(double(100*time.Millisecond), timeout/(500*time.Millisecond))
+
+	var h time.Duration
+	h += time.Minute
+	h *= 2
+	h -= 30 * time.Second
+	fmt.Println(h, h.Minutes(), -h)
+
+	ms := int64(timeout / time.Millisecond)
+	var ns int64 = int64(time.Second)
+	fmt.Println(ms, ns)
+	fmt.Println([]time.Duration{time.Second, 2 * time.Millisecond}, time.Duration(90)*time.Minute)
+}
+
+// Output:
+// 5s 2.5s 1s 3ms true 3ms
+// 5s 3ms 3000000
+// 6.5s 7s 6s 6.5
+// 200ms 10ns
+// 1m30s 1.5 -1m30s
+// 5000 1000000000
+// [1s 2ms] 1h30m0s
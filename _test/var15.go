@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+var sum = a + b
+
+var a, b = pair(base)
+
+var base = 10
+
+var n, err = strconv.Atoi("12")
+
+var v, isInt = iface.(int)
+
+var iface interface{} = 3
+
+var m, ok = lookup["x"]
+
+var lookup = map[string]int{"x": 1}
+
+func pair(x int) (int, int) { return x, x * 2 }
+
+func main() {
+	fmt.Println(a, b, sum)
+	fmt.Println(n, err)
+	fmt.Println(v, isInt, m, ok)
+}
+
+// Output:
+// 10 20 30
+// 12 <nil>
+// 3 true 1 true
@@ -0,0 +1,24 @@
+package main
+
+import "fmt"
+
+type T struct{ a int }
+
+var (
+	s []int
+	t T
+)
+
+func add(i int) { s = append(s, i) }
+
+func set(a int) { t = T{a} }
+
+func main() {
+	add(1)
+	add(2)
+	set(3)
+	fmt.Println(s, t)
+}
+
+// Output:
+// [1 2] {3}
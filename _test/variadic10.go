@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func sum(base int, xs ...int) int {
+	for _, x := range xs {
+		base += x
+	}
+	return base
+}
+
+func count(xs ...interface{}) int { return len(xs) }
+
+func show(prefix string, xs ...interface{}) string { return prefix + fmt.Sprint(xs...) }
+
+func main() {
+	a := []int{1, 2, 3}
+	fmt.Println(sum(10, a...), sum(10), sum(10, 1, 2))
+
+	var n []int
+	fmt.Println(sum(1, n...), sum(1, []int{}...), sum(1, nil...))
+
+	is := []interface{}{1, "a", 2.5}
+	fmt.Println(count(is...), count(is), count(), count(nil), count(is, is))
+	fmt.Println(show("p", is...), show("q", 1, 2))
+
+	var ni []interface{}
+	fmt.Println(count(ni...), show("r", ni...))
+
+	// Spread into binary variadic functions.
+	fmt.Println(fmt.Sprint(is...))
+	args := []interface{}{3, "z"}
+	fmt.Println(fmt.Sprintf("%d-%s", args...))
+	ss := []string{"a", "b"}
+	fmt.Println(strings.Join(append(ss, []string{"c", "d"}...), "-"))
+
+	f := func(s ...string) int { return len(s) }
+	fmt.Println(f(ss...), f(), f(nil...))
+}
+
+// Output:
+// 16 10 13
+// 1 1 1
+// 3 1 0 1 2
+// p1a2.5 q1 2
+// 0 r
+// 1a2.5
+// 3-z
+// a-b-c-d
+// 2 0 0
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"log"
+	"os"
+)
+
+func main() {
+	l := log.New(os.Stdout, "", 0)
+	l.Println("a", 1, 2)
+	l.Printf("%s %d", "b", 3)
+}
+
+// Output:
+// a 1 2
+// b 3
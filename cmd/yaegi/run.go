@@ -104,7 +104,11 @@ func runPackage(i *interp.Interpreter, path string) error {
 }
 
 func runDir(i *interp.Interpreter, path string) error {
-	return fmt.Errorf("runDir not implemented")
+	_, err := i.EvalPath(path)
+	if p, ok := err.(interp.Panic); ok {
+		fmt.Println(string(p.Stack))
+	}
+	return err
 }
 
 func runFile(i *interp.Interpreter, path string) error {
@@ -120,6 +124,9 @@ func runFile(i *interp.Interpreter, path string) error {
 	} else {
 		// Files not starting with "#!" are supposed to be pure Go, directly Evaled.
 		_, err := i.EvalPath(path)
+		if e, ok := err.(interp.ExitError); ok {
+			return e
+		}
 		if err != nil {
 			fmt.Println(err)
 			if p, ok := err.(interp.Panic); ok {
@@ -45,6 +45,13 @@ an explicit import is still necessary).
 Note that the source packages are always interpreted in file mode,
 even if imported from REPL.
 
+The following commands, starting with ":", are also available in REPL mode:
+
+	:vars         list the symbols declared in the main package, with their types
+	:imports      list the packages imported in the main package
+	:save <file>  save the successfully evaluated statements to file
+	:load <file>  evaluate the statements of file, stopping at the first error
+
 The following extract is a valid executable script:
 
 	#!/usr/bin/env yaegi
@@ -90,6 +97,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+
+	"github.com/containous/yaegi/interp"
 )
 
 const (
@@ -128,6 +137,11 @@ func main() {
 		err = run(os.Args[1:])
 	}
 
+	var exit interp.ExitError
+	if errors.As(err, &exit) {
+		os.Exit(exit.Code)
+	}
+
 	if err != nil && !errors.Is(err, flag.ErrHelp) {
 		err = fmt.Errorf("%s: %w", cmd, err)
 		fmt.Fprintln(os.Stderr, err)
@@ -59,7 +59,7 @@ func {{$name}}(n *node) {
 		case c0.rval.IsValid():
 			i := vInt(c0.rval)
 			{{- if $op.Shift}}
-			v1 := genValueUint(c1)
+			v1 := genValueShift(c1)
 			{{else}}
 			v1 := genValueInt(c1)
 			{{end -}}
@@ -83,7 +83,7 @@ func {{$name}}(n *node) {
 		default:
 			v0 := genValueInt(c0)
 			{{- if $op.Shift}}
-			v1 := genValueUint(c1)
+			v1 := genValueShift(c1)
 			{{else}}
 			v1 := genValueInt(c1)
 			{{end -}}
@@ -98,7 +98,11 @@ func {{$name}}(n *node) {
 		switch {
 		case c0.rval.IsValid():
 			i := vUint(c0.rval)
+			{{- if $op.Shift}}
+			v1 := genValueShift(c1)
+			{{else}}
 			v1 := genValueUint(c1)
+			{{end -}}
 			n.exec = func(f *frame) bltn {
 				_, j := v1(f)
 				dest(f).SetUint(i {{$op.Name}} j)
@@ -114,7 +118,11 @@ func {{$name}}(n *node) {
 			}
 		default:
 			v0 := genValueUint(c0)
+			{{- if $op.Shift}}
+			v1 := genValueShift(c1)
+			{{else}}
 			v1 := genValueUint(c1)
+			{{end -}}
 			n.exec = func(f *frame) bltn {
 				_, i := v0(f)
 				_, j := v1(f)
@@ -294,7 +302,7 @@ func {{$name}}Assign(n *node) {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			v0 := genValueInt(c0)
 			{{- if $op.Shift}}
-			v1 := genValueUint(c1)
+			v1 := genValueShift(c1)
 			{{else}}
 			v1 := genValueInt(c1)
 			{{end -}}
@@ -8,6 +8,8 @@ import (
 	"go/parser"
 	"go/scanner"
 	"go/token"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -185,6 +187,138 @@ func (k nkind) String() string {
 	return "nKind(" + strconv.Itoa(int(k)) + ")"
 }
 
+// embedFiles processes the go:embed directives of the package level
+// variables of file f, by setting the content of the embedded file,
+// relative to the directory of name, as the initial value of the variable.
+// Only variables of type string or []byte are supported.
+func (interp *Interpreter) embedFiles(f *ast.File, name string) error {
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR {
+			continue
+		}
+		for _, s := range gd.Specs {
+			vs := s.(*ast.ValueSpec)
+			doc := vs.Doc
+			if doc == nil && len(gd.Specs) == 1 {
+				if doc = gd.Doc; doc == nil {
+					// In incremental mode, the comment may follow the
+					// inserted package clause on the same line, which
+					// prevents it to be a doc comment.
+					doc = interp.commentBefore(f, gd.Pos())
+				}
+			}
+			if doc == nil {
+				continue
+			}
+			var patterns []string
+			var pos token.Pos
+			for _, c := range doc.List {
+				if !strings.HasPrefix(c.Text, "//go:embed ") {
+					continue
+				}
+				p, err := embedPatterns(c.Text[len("//go:embed "):])
+				if err != nil {
+					return astError(fmt.Errorf("%s: %v", interp.fset.Position(c.Pos()), err))
+				}
+				patterns = append(patterns, p...)
+				pos = c.Pos()
+			}
+			if patterns == nil {
+				continue
+			}
+			errorf := func(format string, a ...interface{}) error {
+				return astError(fmt.Errorf("%s: "+format, append([]interface{}{interp.fset.Position(pos)}, a...)...))
+			}
+			switch {
+			case len(vs.Names) > 1:
+				return errorf("go:embed cannot apply to multiple vars")
+			case len(vs.Values) > 0:
+				return errorf("go:embed cannot apply to var with initializer")
+			case vs.Type == nil:
+				return errorf("go:embed cannot apply to var without type")
+			}
+			if t, ok := vs.Type.(*ast.SelectorExpr); ok && t.Sel.Name == "FS" {
+				return errorf("go:embed cannot apply to var of type embed.FS: not supported")
+			}
+
+			var files []string
+			for _, p := range patterns {
+				m, err := filepath.Glob(filepath.Join(filepath.Dir(name), filepath.FromSlash(p)))
+				if err != nil {
+					return errorf("pattern %s: %v", p, err)
+				}
+				if len(m) == 0 {
+					return errorf("pattern %s: no matching files found", p)
+				}
+				files = append(files, m...)
+			}
+			if len(files) > 1 {
+				return errorf("invalid go:embed: multiple files for var %s", vs.Names[0].Name)
+			}
+			b, err := ioutil.ReadFile(files[0])
+			if err != nil {
+				return errorf("%v", err)
+			}
+
+			// Initialize the variable with a conversion of the file content
+			// to its declared type.
+			lit := &ast.BasicLit{ValuePos: vs.Names[0].Pos(), Kind: token.STRING, Value: strconv.Quote(string(b))}
+			vs.Values = []ast.Expr{&ast.CallExpr{Fun: vs.Type, Lparen: lit.ValuePos, Args: []ast.Expr{lit}, Rparen: lit.ValuePos}}
+		}
+	}
+	return nil
+}
+
+// commentBefore returns the comment group of file f ending on the line
+// preceding pos, or nil.
+func (interp *Interpreter) commentBefore(f *ast.File, pos token.Pos) *ast.CommentGroup {
+	line := interp.fset.Position(pos).Line
+	for _, g := range f.Comments {
+		if g.End() < pos && interp.fset.Position(g.End()).Line == line-1 {
+			return g
+		}
+	}
+	return nil
+}
+
+// embedPatterns returns the space separated, possibly quoted, patterns
+// of a go:embed directive.
+func embedPatterns(s string) ([]string, error) {
+	var patterns []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		var p string
+		switch s[0] {
+		case '"', '`':
+			i := strings.IndexByte(s[1:], s[0])
+			for s[0] == '"' && i > 0 && s[i] == '\\' {
+				j := strings.IndexByte(s[i+2:], s[0])
+				if j < 0 {
+					i = -1
+					break
+				}
+				i += j + 1
+			}
+			if i < 0 {
+				return nil, fmt.Errorf("invalid quoted string in go:embed: %s", s)
+			}
+			q, err := strconv.Unquote(s[:i+2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted string in go:embed: %s", s[:i+2])
+			}
+			p, s = q, s[i+2:]
+		default:
+			i := strings.IndexAny(s, " \t")
+			if i < 0 {
+				i = len(s)
+			}
+			p, s = s[:i], s[i:]
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
 // astError represents an error during AST build stage.
 type astError error
 
@@ -251,6 +385,7 @@ const (
 	aStar
 	aSub
 	aSubAssign
+	aTilde
 	aTypeAssert
 	aXor
 	aXorAssign
@@ -311,6 +446,7 @@ var actions = [...]string{
 	aStar:         "*",
 	aSub:          "-",
 	aSubAssign:    "-=",
+	aTilde:        "~",
 	aTypeAssert:   "TypeAssert",
 	aXor:          "^",
 	aXorAssign:    "^=",
@@ -333,8 +469,13 @@ func isAssignAction(a action) bool {
 }
 
 func (interp *Interpreter) firstToken(src string) token.Token {
+	return firstToken(interp.fset, src)
+}
+
+// firstToken returns the first token of src, registered in fset.
+func firstToken(fset *token.FileSet, src string) token.Token {
 	var s scanner.Scanner
-	file := interp.fset.AddFile("", interp.fset.Base(), len(src))
+	file := fset.AddFile("", fset.Base(), len(src))
 	s.Init(file, []byte(src), nil, 0)
 
 	_, tok, _ := s.Scan()
@@ -365,7 +506,7 @@ func wrapInMain(src string) string {
 // interpreter's FileSet.
 func (interp *Interpreter) ast(src, name string, inc bool) (string, *node, error) {
 	var inFunc bool
-	mode := parser.DeclarationErrors
+	mode := parser.DeclarationErrors | parser.ParseComments // comments for go:embed directives
 
 	// Allow incremental parsing of declarations or statements, by inserting
 	// them in a pseudo file package or function. Those statements or
@@ -382,8 +523,6 @@ func (interp *Interpreter) ast(src, name string, inc bool) (string, *node, error
 			inFunc = true
 			src = wrapInMain(src)
 		}
-		// Parse comments in REPL mode, to allow tag setting.
-		mode |= parser.ParseComments
 	}
 
 	if ok, err := interp.buildOk(&interp.context, name, src); !ok || err != nil {
@@ -412,10 +551,15 @@ func (interp *Interpreter) ast(src, name string, inc bool) (string, *node, error
 
 	setYaegiTags(&interp.context, f.Comments)
 
+	if err := interp.embedFiles(f, name); err != nil {
+		return "", nil, err
+	}
+
 	var root *node
 	var anc astNode
 	var st nodestack
 	var pkgName string
+	var tparams []*node // type parameter lists
 
 	addChild := func(root **node, anc astNode, pos token.Pos, kind nkind, act action) *node {
 		var i interface{}
@@ -645,7 +789,11 @@ func (interp *Interpreter) ast(src, name string, inc bool) (string, *node, error
 			st.push(addChild(&root, anc, pos, fieldExpr, aNop), nod)
 
 		case *ast.FieldList:
-			st.push(addChild(&root, anc, pos, fieldList, aNop), nod)
+			n := addChild(&root, anc, pos, fieldList, aNop)
+			if a == typeParams(anc.ast) {
+				tparams = append(tparams, n)
+			}
+			st.push(n, nod)
 
 		case *ast.File:
 			pkgName = a.Name.Name
@@ -712,20 +860,21 @@ func (interp *Interpreter) ast(src, name string, inc bool) (string, *node, error
 			n := addChild(&root, anc, pos, identExpr, aNop)
 			n.ident = a.Name
 			st.push(n, nod)
-			if n.anc.kind == defineStmt && n.anc.nright == 0 {
-				// Implicit assign expression (in a ConstDecl block).
-				// Clone assign source and type from previous
-				a := n.anc
+			if a := n.anc; a.kind == defineStmt && a.nright == 0 && len(a.child) == a.nleft {
+				// Implicit assign expression (in a ConstDecl block), once all
+				// identifiers are parsed. Clone assign source and type from previous.
 				pa := a.anc.child[childPos(a)-1]
 
 				if len(pa.child) > pa.nleft+pa.nright {
 					// duplicate previous type spec
-					a.child = append(a.child, interp.dup(pa.child[a.nleft], a))
+					a.child = append(a.child, interp.dup(pa.child[pa.nleft], a))
 				}
 
-				// duplicate previous assign right hand side
-				a.child = append(a.child, interp.dup(pa.lastChild(), a))
-				a.nright++
+				// duplicate previous assign right hand side expressions
+				for _, c := range pa.child[len(pa.child)-pa.nright:] {
+					a.child = append(a.child, interp.dup(c, a))
+				}
+				a.nright = pa.nright
 			}
 
 		case *ast.IfStmt:
@@ -848,6 +997,10 @@ func (interp *Interpreter) ast(src, name string, inc bool) (string, *node, error
 				act = aNeg
 			case token.XOR:
 				act = aBitNot
+			default:
+				if isTilde(a.Op) {
+					act = aTilde
+				}
 			}
 			st.push(addChild(&root, anc, pos, kind, act), nod)
 
@@ -879,11 +1032,33 @@ func (interp *Interpreter) ast(src, name string, inc bool) (string, *node, error
 			st.push(n, nod)
 
 		default:
+			if isIndexListExpr(a) {
+				// Instantiation of a generic with several type arguments.
+				st.push(addChild(&root, anc, pos, indexExpr, aGetIndex), nod)
+				break
+			}
 			err = astError(fmt.Errorf("ast: %T not implemented, line %s", a, interp.fset.Position(pos)))
 			return false
 		}
 		return true
 	})
+	for _, n := range tparams {
+		// Move type parameters after the other children, to keep the positions of
+		// children of function types and type specs, and the function declaration
+		// layout: [receiver, name, type, body, type parameters].
+		a := n.anc
+		for i, c := range a.child {
+			if c == n {
+				a.child = append(a.child[:i], a.child[i+1:]...)
+				break
+			}
+		}
+		if a.kind == funcType {
+			a = a.anc
+		}
+		n.anc = a
+		a.child = append(a.child, n)
+	}
 	if inFunc {
 		// Incremental parsing: statements were inserted in a pseudo function.
 		// Set root to function body so its statements are evaluated in global scope.
@@ -1,6 +1,7 @@
 package interp
 
 import (
+	"fmt"
 	"go/ast"
 	"go/build"
 	"go/parser"
@@ -9,6 +10,19 @@ import (
 	"strings"
 )
 
+// MatchFile reports whether the file of the given name and content would be
+// evaluated by the interpreter as part of a package directory, according to
+// its name and its build constraints. An error from parser is returned as well.
+func (interp *Interpreter) MatchFile(filename string, content []byte) (bool, error) {
+	if skipFile(&interp.context, filename) {
+		return false, nil
+	}
+	// Work on a copy of the build context, to ignore yaegi:tags settings.
+	ctx := interp.context
+	ctx.BuildTags = append([]string(nil), ctx.BuildTags...)
+	return interp.buildOk(&ctx, filename, string(content))
+}
+
 // buildOk returns true if a file or script matches build constraints
 // as specified in https://golang.org/pkg/go/build/#hdr-Build_Constraints.
 // An error from parser is returned as well.
@@ -18,11 +32,20 @@ func (interp *Interpreter) buildOk(ctx *build.Context, name, src string) (bool,
 	if err != nil {
 		return false, err
 	}
-	for _, g := range f.Comments {
-		// in file, evaluate the AND of multiple line build constraints
-		for _, line := range strings.Split(strings.TrimSpace(g.Text()), "\n") {
-			if !buildLineOk(ctx, line) {
-				return false, nil
+	if expr, found := goBuildExpr(f.Comments); found {
+		// A //go:build line takes precedence over // +build lines.
+		// A malformed expression excludes the file.
+		ok, err := buildExprOk(ctx, expr)
+		if !ok || err != nil {
+			return false, err
+		}
+	} else {
+		for _, g := range f.Comments {
+			// in file, evaluate the AND of multiple line build constraints
+			for _, line := range strings.Split(strings.TrimSpace(g.Text()), "\n") {
+				if !buildLineOk(ctx, line) {
+					return false, nil
+				}
 			}
 		}
 	}
@@ -30,6 +53,121 @@ func (interp *Interpreter) buildOk(ctx *build.Context, name, src string) (bool,
 	return true, nil
 }
 
+// goBuildExpr returns the expression of the first //go:build line
+// in comments, and true if such a line is found.
+func goBuildExpr(comments []*ast.CommentGroup) (string, bool) {
+	for _, g := range comments {
+		for _, c := range g.List {
+			if c.Text == "//go:build" || strings.HasPrefix(c.Text, "//go:build ") || strings.HasPrefix(c.Text, "//go:build\t") {
+				return strings.TrimSpace(c.Text[10:]), true
+			}
+		}
+	}
+	return "", false
+}
+
+// buildExprOk returns true if the boolean expression of a //go:build
+// line is satisfied. Operators are ||, && and !, and parentheses are allowed.
+func buildExprOk(ctx *build.Context, expr string) (bool, error) {
+	p := &buildExprParser{ctx: ctx, s: expr}
+	ok := p.or()
+	if p.err == nil && p.next() != "" {
+		p.err = fmt.Errorf("invalid //go:build expression: %s", expr)
+	}
+	return ok && p.err == nil, p.err
+}
+
+// buildExprParser evaluates a //go:build expression by recursive descent.
+type buildExprParser struct {
+	ctx *build.Context
+	s   string // remaining expression
+	tok string // current token, if already read
+	err error
+}
+
+// next returns the current token, without consuming it.
+func (p *buildExprParser) next() string {
+	if p.tok != "" {
+		return p.tok
+	}
+	p.s = strings.TrimSpace(p.s)
+	switch {
+	case p.s == "":
+		return ""
+	case strings.HasPrefix(p.s, "&&"), strings.HasPrefix(p.s, "||"):
+		p.tok = p.s[:2]
+	case p.s[0] == '!', p.s[0] == '(', p.s[0] == ')':
+		p.tok = p.s[:1]
+	default:
+		i := strings.IndexFunc(p.s, func(r rune) bool {
+			return !(r == '_' || r == '.' || '0' <= r && r <= '9' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z')
+		})
+		if i < 0 {
+			i = len(p.s)
+		}
+		if i == 0 {
+			p.err = fmt.Errorf("invalid //go:build expression: unexpected %q", p.s[0])
+			p.s = ""
+			return ""
+		}
+		p.tok = p.s[:i]
+	}
+	p.s = p.s[len(p.tok):]
+	return p.tok
+}
+
+func (p *buildExprParser) consume() string {
+	t := p.next()
+	p.tok = ""
+	return t
+}
+
+func (p *buildExprParser) or() bool {
+	r := p.and()
+	for p.next() == "||" {
+		p.consume()
+		r = p.and() || r
+	}
+	return r
+}
+
+func (p *buildExprParser) and() bool {
+	r := p.not()
+	for p.next() == "&&" {
+		p.consume()
+		r = p.not() && r
+	}
+	return r
+}
+
+func (p *buildExprParser) not() bool {
+	switch t := p.consume(); t {
+	case "!":
+		if p.next() == "!" && p.err == nil {
+			p.err = fmt.Errorf("invalid //go:build expression: double negation not allowed")
+		}
+		return !p.not()
+	case "(":
+		r := p.or()
+		if p.consume() != ")" && p.err == nil {
+			p.err = fmt.Errorf("invalid //go:build expression: missing )")
+		}
+		return r
+	case "":
+		if p.err == nil {
+			p.err = fmt.Errorf("invalid //go:build expression: unexpected end")
+		}
+		return false
+	case "&&", "||", ")":
+		if p.err == nil {
+			p.err = fmt.Errorf("invalid //go:build expression: unexpected %s", t)
+		}
+		return false
+	default:
+		return buildTagOk(p.ctx, t)
+	}
+}
+
 // buildLineOk returns true if line is not a build constraint or
 // if build constraint is satisfied.
 func buildLineOk(ctx *build.Context, line string) (ok bool) {
@@ -37,6 +37,19 @@ func TestBuildTag(t *testing.T) {
 		{"// +build foo", true},
 		{"// +build !foo", false},
 		{"// +build bar", false},
+		{"//go:build linux && amd64", true},
+		{"//go:build linux && !amd64", false},
+		{"//go:build (windows || linux) && foo", true},
+		{"//go:build !(linux || bar)", false},
+		{"//go:build go1.11 && !go1.12", true},
+		{"//go:build windows\n// +build linux", false},
+		{"//go:build linux\n// +build windows", true},
+		{"//go:build linux &&", false},
+		{"//go:build (linux", false},
+		{"//go:build !!linux", false},
+		{"//go:build !!", false},
+		{"//go:build", false},
+		{"//go:build \n// +build linux", false},
 	}
 
 	i := New(Options{})
@@ -81,6 +94,45 @@ func TestBuildFile(t *testing.T) {
 	}
 }
 
+func TestMatchFile(t *testing.T) {
+	i := New(Options{BuildTags: []string{"foo"}})
+	i.context.GOOS, i.context.GOARCH = "linux", "amd64"
+
+	tests := []struct {
+		name, src string
+		res       bool
+		err       string
+	}{
+		{"bar.go", "package bar", true, ""},
+		{"bar_windows.go", "package bar", false, ""},
+		{"bar_test.go", "package bar", false, ""},
+		{"bar.txt", "package bar", false, ""},
+		{"bar.go", "//go:build foo && linux\n\npackage bar", true, ""},
+		{"bar.go", "// +build !foo\n\npackage bar", false, ""},
+		{"bar.go", "// yaegi:tags baz\n\npackage bar", true, ""},
+		{"bar.go", "//go:build (foo\n\npackage bar", false, "invalid //go:build expression: missing )"},
+		{"bar.go", "//go:build !!foo\n\npackage bar", false, "invalid //go:build expression: double negation not allowed"},
+		{"bar.go", "//go:build\n\npackage bar", false, "invalid //go:build expression: unexpected end"},
+		{"bar.go", "bar", false, "bar.go:1:1: expected 'package', found bar"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.src, func(t *testing.T) {
+			r, err := i.MatchFile(test.name, []byte(test.src))
+			if r != test.res {
+				t.Errorf("got %v, want %v", r, test.res)
+			}
+			if (err == nil) != (test.err == "") || err != nil && err.Error() != test.err {
+				t.Errorf("got error %v, want %q", err, test.err)
+			}
+		})
+	}
+	if contains(i.context.BuildTags, "baz") {
+		t.Error("got build tags changed by MatchFile")
+	}
+}
+
 func Test_goMinorVersion(t *testing.T) {
 	tests := []struct {
 		desc     string
@@ -3,11 +3,13 @@ package interp
 import (
 	"fmt"
 	"go/constant"
+	"go/token"
 	"log"
 	"math"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -20,6 +22,34 @@ type cfgError struct {
 
 func (c *cfgError) Error() string { return c.error.Error() }
 
+// Pos returns the position of the error in source, which can be resolved by
+// the Position method of the interpreter.
+func (c *cfgError) Pos() token.Pos { return c.node.pos }
+
+// ErrorList is a list of compilation errors, returned by an evaluation
+// when more than one error is detected.
+type ErrorList []error
+
+// Error implements the error interface.
+func (e ErrorList) Error() string {
+	switch len(e) {
+	case 0:
+		return "no errors"
+	case 1:
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0], len(e)-1)
+}
+
+// Err returns an error equivalent to this error list.
+// If the list is empty, Err returns nil.
+func (e ErrorList) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
 var constOp = map[action]func(*node){
 	aAdd:    addConst,
 	aSub:    subConst,
@@ -53,10 +83,26 @@ const nilIdent = "nil"
 // variables. A list of nodes of init functions is returned.
 // Following this pass, the CFG is ready to run.
 func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
-	sc := interp.initScopePkg(importPath)
+	return interp.cfgScope(root, interp.initScopePkg(importPath))
+}
+
+// cfgScope generates the control flow graph of root, in scope sc.
+func (interp *Interpreter) cfgScope(root *node, sc *scope) ([]*node, error) {
+	importPath := sc.pkgID
 	check := typecheck{}
 	var initNodes []*node
 	var err error
+	var errs ErrorList // errors of previous function declarations
+	var declSc *scope  // scope of the current function declaration
+
+	// A panic following errors is most likely caused by them, report the errors instead.
+	defer func() {
+		if len(errs) > 0 {
+			if r := recover(); r != nil {
+				initNodes, err = nil, errs
+			}
+		}
+	}()
 
 	baseName := filepath.Base(interp.fset.Position(root.pos).Filename)
 
@@ -103,7 +149,7 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 						switch typ.Kind() {
 						case reflect.Map:
 							n.anc.gen = rangeMap
-							ityp := &itype{cat: valueT, rtype: reflect.TypeOf((*reflect.MapIter)(nil))}
+							ityp := &itype{cat: valueT, rtype: reflect.TypeOf((*mapIterator)(nil)).Elem()}
 							sc.add(ityp)
 							ktyp = &itype{cat: valueT, rtype: typ.Key()}
 							vtyp = &itype{cat: valueT, rtype: typ.Elem()}
@@ -118,7 +164,7 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 						}
 					case mapT:
 						n.anc.gen = rangeMap
-						ityp := &itype{cat: valueT, rtype: reflect.TypeOf((*reflect.MapIter)(nil))}
+						ityp := &itype{cat: valueT, rtype: reflect.TypeOf((*mapIterator)(nil)).Elem()}
 						sc.add(ityp)
 						ktyp = o.typ.key
 						vtyp = o.typ.val
@@ -161,7 +207,8 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 			if len(n.child) > 0 {
 				// Handle labeled statements
 				label := n.child[0].ident
-				if sym, _, ok := sc.lookup(label); ok {
+				fsc := sc.funcScope()
+				if sym, ok := fsc.sym[label]; ok {
 					if sym.kind != labelSym {
 						err = n.child[0].cfgErrorf("label %s not defined", label)
 						break
@@ -170,13 +217,14 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 					n.sym = sym
 				} else {
 					n.sym = &symbol{kind: labelSym, from: []*node{n}, index: -1}
-					sc.sym[label] = n.sym
+					fsc.sym[label] = n.sym
 				}
 			}
 
 		case labeledStmt:
 			label := n.child[0].ident
-			if sym, _, ok := sc.lookup(label); ok {
+			fsc := sc.funcScope()
+			if sym, ok := fsc.sym[label]; ok {
 				if sym.kind != labelSym {
 					err = n.child[0].cfgErrorf("label %s not defined", label)
 					break
@@ -185,7 +233,7 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				n.sym = sym
 			} else {
 				n.sym = &symbol{kind: labelSym, node: n, index: -1}
-				sc.sym[label] = n.sym
+				fsc.sym[label] = n.sym
 			}
 
 		case caseClause:
@@ -222,7 +270,8 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 
 		case commClause:
 			sc = sc.pushBloc()
-			if len(n.child) > 0 && n.child[0].action == aAssign {
+			if len(n.child) > 0 && n.child[0].kind == defineStmt {
+				// Define the variable assigned from channel receive.
 				ch := n.child[0].child[1].child[0]
 				var typ *itype
 				if typ, err = nodeType(interp, sc, ch); err != nil {
@@ -302,6 +351,16 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 			fallthrough
 
 		case funcDecl:
+			if isGeneric(n) {
+				// Generic functions are compiled at instantiation, methods with their type.
+				if !isMethod(n) {
+					err = interp.checkGeneric(sc.sym[n.child[1].ident].typ)
+				}
+				return false
+			}
+			if n.kind == funcDecl && n.anc.kind == fileStmt {
+				declSc = sc
+			}
 			n.val = n
 			// Compute function type before entering local scope to avoid
 			// possible collisions with function argument names.
@@ -358,6 +417,10 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 		case ifStmt0, ifStmt1, ifStmt2, ifStmt3:
 			sc = sc.pushBloc()
 
+		case selectStmt:
+			sc = sc.pushBloc()
+			sc.loop = n
+
 		case switchStmt, switchIfStmt, typeSwitch:
 			// Make sure default clause is in last position.
 			c := n.lastChild().child
@@ -372,6 +435,15 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 			return false
 
 		case typeSpec:
+			if isGeneric(n) {
+				if sc.def != nil {
+					if err = interp.declareGeneric(sc, n, ""); err != nil {
+						return false
+					}
+				}
+				err = interp.checkGeneric(sc.sym[n.child[0].ident].typ)
+				return false
+			}
 			// processing already done in GTA pass for global types, only parses inlined types
 			if sc.def == nil {
 				return false
@@ -389,6 +461,12 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 			if n.child[1].kind == identExpr {
 				n.typ = &itype{cat: aliasT, val: typ, name: typeName}
 			} else {
+				if n.child[1].kind == indexExpr {
+					// Do not rename the instance of a generic type.
+					t := *typ
+					t.method = nil
+					typ = &t
+				}
 				n.typ = typ
 				n.typ.name = typeName
 			}
@@ -415,6 +493,12 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 	}, func(n *node) {
 		// Post-order processing
 		if err != nil {
+			if n.kind == funcDecl && n.anc.kind == fileStmt && len(errs)+1 < interp.maxErrors {
+				// Keep the error and resume at the next declaration. The rest
+				// of the function is skipped, to avoid cascading errors.
+				errs = append(errs, err)
+				err, sc = nil, declSc
+			}
 			return
 		}
 
@@ -443,7 +527,8 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				n.gen = nop
 				break
 			}
-			if n.anc.kind == commClause {
+			if n.anc.kind == commClause && n.anc.child[0] == n {
+				// Channel receive assignment, performed by select.
 				n.gen = nop
 				break
 			}
@@ -508,6 +593,11 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				if err != nil {
 					break
 				}
+				if isShift(src) && !src.rval.IsValid() && src.findex >= 0 && src.findex != dest.findex {
+					// The untyped left operand of a non-constant shift takes the
+					// type of the assignment, update the frame location accordingly.
+					sc.types[src.findex] = src.typ.frameType()
+				}
 
 				if updateSym {
 					sym.typ = dest.typ
@@ -521,10 +611,14 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				n.findex = dest.findex
 				n.level = dest.level
 
+				// A global variable set from a function is not addressed by frame level,
+				// so the source can not be computed directly in the destination.
+				globalDest := sym != nil && sym.global && !sc.global
+
 				// Propagate type
 				// TODO: Check that existing destination type matches source type
 				switch {
-				case n.action == aAssign && isCall(src) && dest.typ.cat != interfaceT && !isMapEntry(dest) && !isRecursiveField(dest):
+				case n.action == aAssign && isCall(src) && !src.rval.IsValid() && dest.typ.cat != interfaceT && !isMapEntry(dest) && !isRecursiveField(dest) && !globalDest && !(isBinInterface(dest.typ) && src.typ.cat != valueT):
 					// Call action may perform the assignment directly.
 					n.gen = nop
 					src.level = level
@@ -532,12 +626,12 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 					if src.typ.untyped && !dest.typ.untyped {
 						src.typ = dest.typ
 					}
-				case n.action == aAssign && src.action == aRecv:
+				case n.action == aAssign && src.action == aRecv && !globalDest:
 					// Assign by reading from a receiving channel.
 					n.gen = nop
 					src.findex = dest.findex // Set recv address to LHS
 					dest.typ = src.typ
-				case n.action == aAssign && src.action == aCompositeLit && !isMapEntry(dest):
+				case n.action == aAssign && src.action == aCompositeLit && !isMapEntry(dest) && !globalDest:
 					if dest.typ.cat == valueT && dest.typ.rtype.Kind() == reflect.Interface {
 						// Skip optimisation for assigned binary interface or map entry
 						// which require and additional operation to set the value
@@ -559,7 +653,11 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				}
 				n.level = level
 				if isMapEntry(dest) {
-					dest.gen = nop // skip getIndexMap
+					if n.action == aAssign {
+						dest.gen = nop // skip getIndexMap
+					} else {
+						storeMapEntry(n, dest)
+					}
 				}
 				if n.anc.kind == constDecl {
 					n.gen = nop
@@ -567,11 +665,14 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 					if sym, _, ok := sc.lookup(dest.ident); ok {
 						sym.kind = constSym
 					}
-					if childPos(n) == len(n.anc.child)-1 {
-						sc.iota = 0
-					} else {
-						sc.iota++
-					}
+				}
+			}
+			if n.anc.kind == constDecl {
+				// iota is incremented once per ConstSpec.
+				if childPos(n) == len(n.anc.child)-1 {
+					sc.iota = 0
+				} else {
+					sc.iota++
 				}
 			}
 
@@ -584,6 +685,9 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				sym.typ = n.typ
 				n.level = level
 			}
+			if isMapEntry(n.child[0]) {
+				storeMapEntry(n, n.child[0])
+			}
 
 		case assignXStmt:
 			wireChild(n)
@@ -616,10 +720,6 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 
 		case defineXStmt:
 			wireChild(n)
-			if sc.def == nil {
-				// In global scope, type definition already handled by GTA.
-				break
-			}
 			err = compDefineX(sc, n)
 
 		case binaryExpr:
@@ -658,6 +758,9 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 			if c0.rval.IsValid() && c1.rval.IsValid() && !isInterface(n.typ) && constOp[n.action] != nil {
 				n.typ.TypeOf()       // Force compute of reflection type.
 				constOp[n.action](n) // Compute a constant result now rather than during exec.
+				if err = check.constValue(n); err != nil {
+					break
+				}
 			}
 			switch {
 			case n.rval.IsValid():
@@ -684,6 +787,27 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 			}
 
 		case indexExpr:
+			if t := n.child[0].typ; t != nil && t.cat == genericT {
+				if t.node != nil && t.node.kind == funcDecl && isCall(n.anc) && n.anc.child[0] == n {
+					// The instantiation is completed by inference at call.
+					n.typ = t
+					break
+				}
+				// Instantiation of a generic function or type.
+				var types []*itype
+				if types, _, err = typeArgs(interp, sc, n.child[1:]); err != nil {
+					break
+				}
+				var sym *symbol
+				if sym, err = interp.instantiate(t, types, n); err != nil {
+					break
+				}
+				n.typ, n.findex, n.gen = sym.typ, -1, nop
+				if sym.kind == funcSym {
+					n.val = sym.node
+				}
+				break
+			}
 			wireChild(n)
 			t := n.child[0].typ
 			switch t.cat {
@@ -769,28 +893,52 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 
 		case breakStmt:
 			if len(n.child) > 0 {
-				gotoLabel(n.sym)
+				if err = checkBranchLabel(n); err == nil {
+					gotoLabel(n.sym)
+				}
 			} else {
 				n.tnext = sc.loop
 			}
 
 		case continueStmt:
 			if len(n.child) > 0 {
-				gotoLabel(n.sym)
+				if err = checkBranchLabel(n); err == nil {
+					gotoLabel(n.sym)
+				}
 			} else {
 				n.tnext = sc.loopRestart
 			}
 
 		case gotoStmt:
+			if n.sym.node != nil {
+				err = checkGoto(n)
+			}
 			gotoLabel(n.sym)
 
 		case labeledStmt:
 			wireChild(n)
 			n.start = n.child[1].start
+			for _, c := range n.sym.from {
+				if c.kind == gotoStmt && err == nil {
+					err = checkGoto(c)
+				}
+			}
 			gotoLabel(n.sym)
 
 		case callExpr:
 			wireChild(n)
+			if c0 := n.child[0]; c0.typ != nil && c0.typ.cat == genericT {
+				// Call of a generic function, instantiated with explicit or inferred type arguments.
+				var sym *symbol
+				if sym, err = interp.callInstance(sc, n); err != nil {
+					break
+				}
+				if sym == nil {
+					err = n.cfgErrorf("undefined type")
+					break
+				}
+				c0.typ, c0.val, c0.findex, c0.sym, c0.gen = sym.typ, sym.node, -1, nil, nop
+			}
 			switch {
 			case interp.isBuiltinCall(n):
 				err = check.builtin(n.child[0].ident, n, n.child[1:], n.action == aCallSlice)
@@ -813,8 +961,11 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				default:
 					n.findex = sc.add(n.typ)
 				}
-				if op, ok := constBltn[n.child[0].ident]; ok && n.anc.action != aAssign {
-					op(n) // pre-compute non-assigned constant :
+				if op, ok := constBltn[n.child[0].ident]; ok {
+					op(n) // pre-compute constant result
+					if n.rval.IsValid() {
+						n.findex = -1
+					}
 				}
 			case n.child[0].isType(sc):
 				// Type conversion expression
@@ -850,6 +1001,10 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 					n.findex = -1
 					n.typ = c0.typ
 					n.rval = c1.rval
+					if _, ok := c1.rval.Interface().(constant.Value); !ok {
+						// The value of a typed constant must be converted.
+						n.rval = c1.rval.Convert(c0.typ.TypeOf())
+					}
 				default:
 					n.gen = convert
 					n.typ = c0.typ
@@ -1095,6 +1250,7 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 			n.start = n.child[3].start
 			n.types = sc.types
 			sc = sc.pop()
+			renewLoopVars(n)
 			funcName := n.child[1].ident
 			if sym := sc.sym[funcName]; !isMethod(n) && sym != nil {
 				sym.index = -1 // to force value to n.val
@@ -1106,6 +1262,7 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 		case funcLit:
 			n.types = sc.types
 			sc = sc.pop()
+			renewLoopVars(n)
 			err = genRun(n)
 
 		case deferStmt, goStmt:
@@ -1130,6 +1287,14 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 					break
 				}
 			}
+			if err = genericUse(n, sym.typ); err != nil {
+				break
+			}
+			if sym.kind == typeSym {
+				if err = constraintUse(n, sym.typ); err != nil {
+					break
+				}
+			}
 			// Found symbol, populate node info
 			n.typ, n.findex, n.level = sym.typ, sym.index, level
 			if n.findex < 0 {
@@ -1403,7 +1568,9 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				// Resolve binary package symbol: a type or a value
 				name := n.child[1].ident
 				pkg := n.child[0].sym.typ.path
-				if s, ok := interp.binPkg[pkg][name]; ok {
+				if !interp.isAllowed(pkg) {
+					err = n.cfgErrorf("use of package %q denied: package not allowed", pkg)
+				} else if s, ok := interp.binPkg[pkg][name]; ok {
 					if isBinType(s) {
 						n.typ = &itype{cat: valueT, rtype: s.Type().Elem()}
 					} else {
@@ -1426,6 +1593,7 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 					n.typ = sym.typ
 					n.sym = sym
 					n.rval = sym.rval
+					err = genericUse(n, sym.typ)
 				} else {
 					err = n.cfgErrorf("undefined selector: %s.%s", pkg, name)
 				}
@@ -1434,11 +1602,10 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				if n.child[0].isType(sc) {
 					// Handle method as a function with receiver in 1st argument
 					n.val = m
-					n.findex = -1
-					n.gen = nop
 					n.typ = &itype{}
 					*n.typ = *m.typ
 					n.typ.arg = append([]*itype{n.child[0].typ}, m.typ.arg...)
+					n.gen = getMethodExpr
 				} else {
 					// Handle method with receiver
 					n.gen = getMethod
@@ -1456,11 +1623,15 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				n.recv = &receiver{node: n.child[0], index: lind}
 				n.val = append([]int{m.Index}, lind...)
 				n.typ = &itype{cat: valueT, rtype: m.Type, recv: n.child[0].typ}
+				if n.child[0].typ.fieldSeq(lind).TypeOf().Kind() == reflect.Interface {
+					// Method of an embedded binary interface: no receiver in signature.
+					n.typ.recv = nil
+				}
 			} else if ti := n.typ.lookupField(n.child[1].ident); len(ti) > 0 {
 				// Handle struct field
 				n.val = ti
 				switch {
-				case isInterfaceSrc(n.typ):
+				case isInterfaceSrc(n.typ) || n.typ.embeddedInterface(ti) != nil:
 					n.typ = n.typ.fieldSeq(ti)
 					n.gen = getMethodByName
 					n.action = aMethod
@@ -1526,9 +1697,11 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 					cur.tnext = an.start
 				}
 				if pn != nil {
-					// Chain channect init action to send data init action.
-					// (already done by wireChild, but let's be explicit).
-					an.tnext = pn
+					if pn != an {
+						// Chain channel init action to send data init action.
+						// (already done by wireChild, but let's be explicit).
+						an.tnext = pn.start
+					}
 					cur = pn
 				}
 			}
@@ -1539,6 +1712,7 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 				// Select is called after the last channel init action.
 				cur.tnext = n.child[0]
 			}
+			sc = sc.pop()
 
 		case starExpr:
 			switch {
@@ -1588,6 +1762,11 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 			fallthrough
 
 		case switchStmt:
+			if n.kind == switchStmt {
+				if err = checkCases(n); err != nil {
+					return
+				}
+			}
 			sc = sc.pop()
 			sbn := n.lastChild() // switch block node
 			clauses := sbn.child
@@ -1632,6 +1811,9 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 			n.child[0].tnext = sbn.start
 
 		case switchIfStmt: // like an if-else chain
+			if err = checkCases(n); err != nil {
+				return
+			}
 			sc = sc.pop()
 			sbn := n.lastChild() // switch block node
 			clauses := sbn.child
@@ -1743,6 +1925,9 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 			if n.child[0].rval.IsValid() && !isInterface(n.typ) && constOp[n.action] != nil {
 				n.typ.TypeOf() // init reflect type
 				constOp[n.action](n)
+				if err = check.constValue(n); err != nil {
+					break
+				}
 			}
 			switch {
 			case n.rval.IsValid():
@@ -1787,6 +1972,15 @@ func (interp *Interpreter) cfg(root *node, importPath string) ([]*node, error) {
 	if sc != interp.universe {
 		sc.pop()
 	}
+	if len(errs) > 0 {
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if len(errs) > 1 {
+			return nil, errs
+		}
+		return nil, errs[0]
+	}
 	return initNodes, err
 }
 
@@ -1817,7 +2011,11 @@ func compDefineX(sc *scope, n *node) error {
 		n.gen = nop
 
 	case indexExpr:
-		types = append(types, src.typ, sc.getType("bool"))
+		typ, err := srcType(sc, src)
+		if err != nil {
+			return err
+		}
+		types = append(types, typ, sc.getType("bool"))
 		n.child[l].gen = getIndexMap2
 		n.gen = nop
 
@@ -1827,12 +2025,20 @@ func compDefineX(sc *scope, n *node) error {
 		} else {
 			n.child[l].gen = typeAssert2
 		}
-		types = append(types, n.child[l].child[1].typ, sc.getType("bool"))
+		typ, err := srcType(sc, src.child[1])
+		if err != nil {
+			return err
+		}
+		types = append(types, typ, sc.getType("bool"))
 		n.gen = nop
 
 	case unaryExpr:
 		if n.child[l].action == aRecv {
-			types = append(types, src.typ, sc.getType("bool"))
+			typ, err := srcType(sc, src)
+			if err != nil {
+				return err
+			}
+			types = append(types, typ, sc.getType("bool"))
 			n.child[l].gen = recv2
 			n.gen = nop
 		}
@@ -1842,8 +2048,19 @@ func compDefineX(sc *scope, n *node) error {
 	}
 
 	for i, t := range types {
+		if sym, _, ok := sc.lookup(n.child[i].ident); ok && sym.global && sym.node == n {
+			// Global variable already defined by GTA.
+			n.child[i].typ = sym.typ
+			n.child[i].findex = sym.index
+			continue
+		}
 		index := sc.add(t)
-		sc.sym[n.child[i].ident] = &symbol{index: index, kind: varSym, typ: t}
+		sym := &symbol{index: index, kind: varSym, typ: t}
+		if sc.global {
+			// Global variables are initialized in dependency order, see genGlobalVarDecl.
+			sym.global, sym.node = true, n
+		}
+		sc.sym[n.child[i].ident] = sym
 		n.child[i].typ = t
 		n.child[i].findex = index
 	}
@@ -1851,6 +2068,15 @@ func compDefineX(sc *scope, n *node) error {
 	return nil
 }
 
+// srcType returns the type of n, which is computed during GTA for global
+// definitions, as the CFG is not yet available.
+func srcType(sc *scope, n *node) (*itype, error) {
+	if n.typ != nil {
+		return n.typ, nil
+	}
+	return nodeType(n.interp, sc, n)
+}
+
 // TODO used for allocation optimization, temporarily disabled
 // func isAncBranch(n *node) bool {
 //	switch n.anc.kind {
@@ -1887,6 +2113,11 @@ func genRun(nod *node) error {
 			return false
 		}
 		switch n.kind {
+		case funcDecl:
+			if isGeneric(n) {
+				// Generic functions are compiled at instantiation.
+				return false
+			}
 		case funcType:
 			if len(n.anc.child) == 4 {
 				// function body entry point
@@ -1987,6 +2218,57 @@ func getVarDependencies(nod *node, sc *scope) (deps []*node) {
 	return deps
 }
 
+// checkCases returns an error if the switch statement n has duplicate
+// constant case values. As in Go, boolean constants may be repeated, so
+// duplicates are never reported in a switch without tag.
+func checkCases(n *node) error {
+	usedCase := map[string]*node{}
+	for _, c := range n.lastChild().child {
+		if len(c.child) == 0 {
+			continue
+		}
+		for _, v := range c.child[:len(c.child)-1] {
+			if !v.rval.IsValid() || isBool(v.typ) {
+				continue
+			}
+			key := caseKey(v.rval)
+			if p, ok := usedCase[key]; ok {
+				prev := n.interp.fset.Position(p.pos)
+				return v.cfgErrorf("duplicate case %s in switch\n\tprevious case at %v", caseName(v), prev)
+			}
+			usedCase[key] = v
+		}
+	}
+	return nil
+}
+
+// caseKey returns a string representation of a constant case value, used to
+// detect duplicate cases in switch statements.
+func caseKey(v reflect.Value) string {
+	if c := vConstantValue(v); c != nil {
+		switch c.Kind() {
+		case constant.String:
+			return strconv.Quote(constant.StringVal(c))
+		case constant.Float:
+			f, _ := constant.Float64Val(c)
+			return fmt.Sprint(f)
+		}
+		return c.ExactString()
+	}
+	if v.Kind() == reflect.String {
+		return strconv.Quote(v.String())
+	}
+	return fmt.Sprint(v)
+}
+
+// caseName returns the name of a constant case value for error messages.
+func caseName(n *node) string {
+	if n.kind == identExpr {
+		return n.ident
+	}
+	return caseKey(n.rval)
+}
+
 // setFnext sets the cond fnext field to next, propagates it for parenthesis blocks
 // and sets the action to branch.
 func setFNext(cond, next *node) {
@@ -2048,6 +2330,9 @@ func (n *node) isType(sc *scope) bool {
 		}
 	case identExpr:
 		return sc.getType(n.ident) != nil
+	case indexExpr:
+		// Instantiation of a generic type.
+		return n.child[0].isType(sc)
 	}
 	return false
 }
@@ -2256,6 +2541,10 @@ func isCall(n *node) bool {
 	return n.action == aCall || n.action == aCallSlice
 }
 
+func isShift(n *node) bool {
+	return n.action == aShl || n.action == aShr
+}
+
 func isBinCall(n *node) bool {
 	return n.kind == callExpr && n.child[0].typ.cat == valueT && n.child[0].typ.rtype.Kind() == reflect.Func
 }
@@ -2294,6 +2583,150 @@ func canExport(name string) bool {
 	return false
 }
 
+// renewLoopVars arranges for the variables of loops in the body of the
+// function def which are captured by function literals to be reallocated:
+// the loop variables at each iteration, and the variables defined in a loop
+// body at each definition. Closures created in a loop body then capture
+// variables specific to an iteration.
+func renewLoopVars(def *node) {
+	type renewal struct {
+		n       *node // node before which the variables are renewed
+		indexes []int // frame indexes of the variables
+	}
+	var renewals []renewal
+	lits := map[*node]bool{} // function literals capturing renewed variables
+
+	add := func(n, body *node, vars []*node) {
+		var indexes []int
+		body.Walk(func(c *node) bool {
+			if c.kind != funcLit {
+				return true
+			}
+			for _, v := range vars {
+				if v.findex < 0 || v.level != 0 || !captures(c, v) {
+					continue
+				}
+				lits[c] = true
+				if !containsIndex(indexes, v.findex) {
+					indexes = append(indexes, v.findex)
+				}
+			}
+			return false
+		}, nil)
+		if len(indexes) > 0 {
+			renewals = append(renewals, renewal{n, indexes})
+		}
+	}
+
+	def.lastChild().Walk(func(n *node) bool {
+		switch n.kind {
+		case funcLit:
+			// Processed on its own.
+			return false
+		case forStmt3a, forStmt4:
+			if init := n.child[0]; init.kind == defineStmt || init.kind == defineXStmt {
+				add(n.child[len(n.child)-2].start, n.lastChild(), init.child[:init.nleft])
+			}
+		case rangeStmt:
+			add(n, n.lastChild(), n.child[:len(n.child)-2])
+		case defineStmt, defineXStmt:
+			if body := loopBody(n); body != nil {
+				add(n.start, body, n.child[:n.nleft])
+			}
+		}
+		return true
+	}, nil)
+
+	// Closures are set before renewals, which may apply to the same nodes.
+	for lit := range lits {
+		lit.gen = getClosure
+	}
+	for _, r := range renewals {
+		indexes := r.indexes
+		gen := r.n.gen
+		r.n.gen = func(n *node) {
+			gen(n)
+			exec := n.exec
+			n.exec = func(f *frame) bltn {
+				f.renew(indexes)
+				return exec(f)
+			}
+		}
+	}
+}
+
+// captures returns true if the function literal lit refers to the variable v
+// of the enclosing frame.
+func captures(lit, v *node) bool {
+	found := false
+	depth := 0
+	lit.Walk(func(c *node) bool {
+		switch {
+		case found:
+			return false
+		case c.kind == funcLit:
+			depth++
+		case c.kind == identExpr && c.ident == v.ident && c.findex == v.findex && c.level == depth:
+			found = true
+		}
+		return true
+	}, func(c *node) {
+		if c.kind == funcLit {
+			depth--
+		}
+	})
+	return found
+}
+
+func containsIndex(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// storeMapEntry arranges for the map entry dest, which is read and updated
+// in place by the operator node n (inc/dec or operation assign), to be
+// written back to the map once the operation is performed.
+func storeMapEntry(n, dest *node) {
+	gen := n.gen
+	n.gen = func(n *node) {
+		gen(n)
+		exec := n.exec
+		value0 := genValue(dest.child[0]) // map
+		value := genValue(dest)
+		var key func(*frame) reflect.Value
+		if dest.child[1].typ.cat == interfaceT {
+			key = genValueInterface(dest.child[1])
+		} else {
+			key = genValue(dest.child[1])
+		}
+		n.exec = func(f *frame) bltn {
+			next := exec(f)
+			value0(f).SetMapIndex(key(f), value(f))
+			return next
+		}
+	}
+}
+
+// loopBody returns the body of the innermost loop of the current function
+// which contains n, or nil if n is not part of a loop body.
+func loopBody(n *node) *node {
+	for c, a := n, n.anc; a != nil; c, a = a, a.anc {
+		switch a.kind {
+		case funcDecl, funcLit:
+			return nil
+		case forStmt0, forStmt1, forStmt2, forStmt3, forStmt3a, forStmt4, forRangeStmt, rangeStmt:
+			if c == a.lastChild() {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
 func getExec(n *node) bltn {
 	if n == nil {
 		return nil
@@ -2349,11 +2782,79 @@ func gotoLabel(s *symbol) {
 	if s.node == nil {
 		return
 	}
+	stmt := s.node.child[1] // labeled statement
 	for _, c := range s.from {
-		c.tnext = s.node.start
+		switch c.kind {
+		case breakStmt:
+			// Exit the labeled loop, switch or select statement.
+			c.tnext = stmt
+		case continueStmt:
+			// Restart the labeled loop, as for an unlabeled continue.
+			switch stmt.kind {
+			case forStmt0, forRangeStmt:
+				c.tnext = stmt.child[0]
+			default:
+				c.tnext = stmt.lastChild()
+			}
+		default:
+			c.tnext = s.node.start
+		}
 	}
 }
 
+// checkBranchLabel verifies that the label of the break or continue
+// statement n refers to an enclosing loop, or for break, to an enclosing
+// switch or select statement.
+func checkBranchLabel(n *node) error {
+	if l := n.sym.node; l != nil {
+		stmt := l.child[1] // labeled statement
+		for a := n.anc; a != nil && a.kind != funcDecl && a.kind != funcLit; a = a.anc {
+			if a != stmt {
+				continue
+			}
+			switch stmt.kind {
+			case forStmt0, forStmt1, forStmt2, forStmt3, forStmt3a, forStmt4, forRangeStmt, rangeStmt:
+				return nil
+			case selectStmt, switchStmt, switchIfStmt, typeSwitch:
+				if n.kind == breakStmt {
+					return nil
+				}
+			}
+			break
+		}
+	}
+	kind := "break"
+	if n.kind == continueStmt {
+		kind = "continue"
+	}
+	return n.child[0].cfgErrorf("invalid %s label %s", kind, n.child[0].ident)
+}
+
+// checkGoto verifies that the goto statement g neither jumps into a block
+// nor over a variable declaration of the block containing its label.
+func checkGoto(g *node) error {
+	label := g.sym.node
+	block := label.anc
+	stmt := g
+	for stmt != nil && stmt.anc != block {
+		stmt = stmt.anc
+	}
+	if stmt == nil {
+		return g.child[0].cfgErrorf("goto %s jumps into block", label.child[0].ident)
+	}
+	from, to := childPos(stmt), childPos(label)
+	if from >= to {
+		return nil // backward jump
+	}
+	for _, c := range block.child[from+1 : to] {
+		if c.kind == defineStmt || c.kind == defineXStmt || c.kind == declStmt && c.child[0].kind == varDecl {
+			line := g.interp.fset.Position(c.pos).Line
+			return g.child[0].cfgErrorf("goto %s jumps over variable declaration at line %d", label.child[0].ident, line)
+		}
+	}
+	return nil
+}
+
 func compositeGenerator(n *node, typ *itype) (gen bltnGenerator) {
 	switch typ.cat {
 	case aliasT, ptrT:
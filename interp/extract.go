@@ -0,0 +1,62 @@
+package interp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Extract returns the symbols of the binary package path, in the form
+// expected by Use, from a map of symbol names to Go values:
+//
+//   - a function is exported as is, e.g. "Println": fmt.Println,
+//   - a nil pointer declares a type, e.g. "Buffer": (*bytes.Buffer)(nil)
+//     or "Writer": (*io.Writer)(nil) for an interface,
+//   - a non nil pointer exports the pointed variable, e.g. "Stdout": &os.Stdout,
+//   - any other value is exported as a constant, e.g. "MaxInt8": math.MaxInt8.
+//
+// It provides the same result as the goexports generator, without requiring
+// code generation at build time. However, the wrapper types allowing interpreted
+// types to implement an interface of the package can not be created at run time.
+// They must be provided as for a type, under the interface name prefixed with
+// "_", e.g. "_Writer": (*_io_Writer)(nil), where _io_Writer is a struct type as
+// generated by goexports.
+func Extract(path string, symbols map[string]interface{}) (Exports, error) {
+	syms := make(map[string]reflect.Value, len(symbols))
+	for name, sym := range symbols {
+		if sym == nil {
+			return nil, fmt.Errorf("%s.%s: nil value", path, name)
+		}
+		v := reflect.ValueOf(sym)
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		syms[name] = v
+	}
+
+	// Check wrappers against the interfaces they implement.
+	for name, v := range syms {
+		if !strings.HasPrefix(name, "_") {
+			continue
+		}
+		it, ok := syms[name[1:]]
+		if !ok || it.Kind() != reflect.Ptr || !it.IsNil() || it.Type().Elem().Kind() != reflect.Interface {
+			return nil, fmt.Errorf("%s.%s: no interface %s for wrapper", path, name, name[1:])
+		}
+		if v.Kind() != reflect.Ptr || !v.IsNil() || v.Type().Elem().Kind() != reflect.Struct {
+			return nil, fmt.Errorf("%s.%s: wrapper is not a nil pointer to struct", path, name)
+		}
+		wt, t := v.Type().Elem(), it.Type().Elem()
+		if !wt.Implements(t) || wt.NumField() != t.NumMethod() {
+			return nil, fmt.Errorf("%s.%s: invalid wrapper for %s", path, name, t)
+		}
+		for i := 0; i < t.NumMethod(); i++ {
+			// Wrapper fields are the interface methods, in the same order.
+			if f, m := wt.Field(i), t.Method(i); f.Name != "W"+m.Name || f.Type != m.Type {
+				return nil, fmt.Errorf("%s.%s: invalid wrapper field %s for method %s", path, name, f.Name, m.Name)
+			}
+		}
+	}
+
+	return Exports{path: syms}, nil
+}
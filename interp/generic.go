@@ -0,0 +1,969 @@
+package interp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Generic functions, methods and types are not compiled at declaration.
+// Each instantiation with a new list of type arguments produces a copy of
+// the declaration, where type parameters are bound to the type arguments,
+// which is then compiled as a regular declaration.
+//
+// A generic declaration is checked by compiling copies where each type
+// parameter is bound to a type of its constraint type set, named after the
+// type parameter, so operations not permitted by the constraint are reported
+// even if the generic is never instantiated.
+
+// instance is a copy of a generic declaration, pending compilation.
+type instance struct {
+	node *node   // function or method declaration
+	sc   *scope  // scope where type parameters are bound
+	gen  *itype  // generic function or type
+	key  string  // name of the function or type instance in the scope of gen
+	sym  *symbol // function instance symbol, or nil for a method
+}
+
+// typeTerm is a type element of the type set of a constraint interface.
+type typeTerm struct {
+	typ   *itype
+	tilde bool // true for all types of same underlying type as typ
+}
+
+func (t typeTerm) String() string {
+	if t.tilde {
+		return "~" + typeString(t.typ)
+	}
+	return typeString(t.typ)
+}
+
+// includes returns true if type typ belongs to the type set of term t.
+func (t typeTerm) includes(typ *itype) bool {
+	if t.tilde {
+		return underlyingID(typ) == underlyingID(t.typ)
+	}
+	return typeKey(typ) == typeKey(t.typ)
+}
+
+func termsString(terms []typeTerm) string {
+	s := make([]string, len(terms))
+	for i, t := range terms {
+		s[i] = t.String()
+	}
+	return strings.Join(s, " | ")
+}
+
+// intersectTerms returns the terms of the intersection of the type sets a and
+// b, where nil stands for the set of all types.
+func intersectTerms(a, b []typeTerm) []typeTerm {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	res := []typeTerm{}
+	for _, x := range a {
+		for _, y := range b {
+			switch {
+			case y.includes(x.typ) && (!x.tilde || y.tilde):
+				res = append(res, x)
+			case x.includes(y.typ) && (!y.tilde || x.tilde):
+				res = append(res, y)
+			}
+		}
+	}
+	return res
+}
+
+// isTypeTerms returns true if n is an union or an approximation element of a
+// constraint interface.
+func isTypeTerms(n *node) bool {
+	return n.kind == binaryExpr && n.action == aOr || n.kind == unaryExpr && n.action == aTilde
+}
+
+// typeTerms returns the terms of the constraint type element n.
+func typeTerms(interp *Interpreter, sc *scope, n *node) ([]typeTerm, error) {
+	switch {
+	case n.kind == binaryExpr && n.action == aOr:
+		l, err := typeTerms(interp, sc, n.child[0])
+		if err != nil {
+			return nil, err
+		}
+		r, err := typeTerms(interp, sc, n.child[1])
+		if err != nil {
+			return nil, err
+		}
+		return append(l, r...), nil
+	case n.kind == unaryExpr && n.action == aTilde:
+		t, err := nodeType(interp, sc, n.child[0])
+		if err != nil {
+			return nil, err
+		}
+		if !t.incomplete && typeKey(t) != underlyingID(t) {
+			return nil, n.cfgErrorf("invalid use of ~ (underlying type of %s is %s)", typeString(t), underlyingID(t))
+		}
+		return []typeTerm{{typ: t, tilde: true}}, nil
+	case n.kind == parenExpr:
+		return typeTerms(interp, sc, n.child[0])
+	}
+	t, err := nodeType(interp, sc, n)
+	if err != nil {
+		return nil, err
+	}
+	if t.cat == interfaceT && t.terms != nil {
+		return t.terms, nil
+	}
+	return []typeTerm{{typ: t}}, nil
+}
+
+// constraintType returns the type of the constraint node n of a type parameter.
+func constraintType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
+	if isTypeTerms(n) {
+		terms, err := typeTerms(interp, sc, n)
+		if err != nil {
+			return nil, err
+		}
+		return &itype{cat: interfaceT, terms: terms, node: n, scope: sc}, nil
+	}
+	t, err := nodeType(interp, sc, n)
+	if err != nil {
+		return nil, err
+	}
+	if t.cat != comparableT && !isInterface(t) {
+		// A non interface constraint restricts the type set to this type.
+		return &itype{cat: interfaceT, terms: []typeTerm{{typ: t}}, node: n, scope: sc}, nil
+	}
+	return t, nil
+}
+
+// requiresComparable returns true if the constraint t is or embeds comparable.
+func requiresComparable(t *itype) bool {
+	switch t.cat {
+	case comparableT:
+		return true
+	case aliasT:
+		return requiresComparable(t.val)
+	case interfaceT:
+		for _, f := range t.field {
+			if f.embed && requiresComparable(f.typ) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkConstraint returns an error at node pos if type t does not satisfy the
+// constraint c, named name.
+func checkConstraint(pos *node, t, c *itype, name string) error {
+	if t.constraint != nil {
+		// A type parameter is checked at instantiation of its own generic.
+		return nil
+	}
+	ts := typeString(t)
+	if c.terms != nil {
+		found := false
+		for _, term := range c.terms {
+			if term.includes(t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			if len(c.terms) == 0 {
+				return pos.cfgErrorf("%s does not satisfy %s (empty type set)", ts, name)
+			}
+			for _, term := range c.terms {
+				if !term.tilde && underlyingID(t) == typeKey(term.typ) {
+					return pos.cfgErrorf("%s does not satisfy %s (possibly missing ~ for %s in %s)", ts, name, typeString(term.typ), name)
+				}
+			}
+			return pos.cfgErrorf("%s does not satisfy %s (%s missing in %s)", ts, name, ts, termsString(c.terms))
+		}
+	}
+	if requiresComparable(c) && !t.comparable() {
+		return pos.cfgErrorf("%s does not satisfy %s", ts, name)
+	}
+	want := c.methods()
+	names := make([]string, 0, len(want))
+	for m := range want {
+		names = append(names, m)
+	}
+	sort.Strings(names)
+	have := t.methods()
+	for _, m := range names {
+		switch sig, ok := have[m]; {
+		case !ok:
+			return pos.cfgErrorf("%s does not satisfy %s (missing method %s)", ts, name, m)
+		case sig != want[m]:
+			return pos.cfgErrorf("%s does not satisfy %s (wrong type for method %s)", ts, name, m)
+		}
+	}
+	return nil
+}
+
+// constraintName returns the name of the constraint n of type t, for error messages.
+func constraintName(n *node, t *itype) string {
+	switch n.kind {
+	case identExpr:
+		return n.ident
+	case selectorExpr:
+		return n.child[0].ident + "." + n.child[1].ident
+	case interfaceType:
+		var elems []string
+		for _, f := range t.field {
+			if f.embed {
+				elems = append(elems, f.name)
+			} else {
+				elems = append(elems, f.name+strings.TrimPrefix(f.typ.TypeOf().String(), "func"))
+			}
+		}
+		if t.terms != nil {
+			elems = append(elems, termsString(t.terms))
+		}
+		return "interface{" + strings.Join(elems, "; ") + "}"
+	}
+	if t.terms != nil {
+		return termsString(t.terms)
+	}
+	return typeString(t)
+}
+
+// typeString returns the representation of type t in error messages.
+func typeString(t *itype) string {
+	switch {
+	case t.cat == valueT:
+		return t.rtype.String()
+	case t.name != "":
+		return t.name
+	}
+	return t.TypeOf().String()
+}
+
+// typeKey returns the identifier of type t in the names of instances.
+func typeKey(t *itype) string {
+	if t.constraint != nil {
+		// Distinguish the type parameters of different checks.
+		return fmt.Sprintf("%s@%p", t.name, t)
+	}
+	if t.cat == valueT && t.rtype.Name() == "" {
+		return t.rtype.String()
+	}
+	return t.id()
+}
+
+// underlyingID returns the identifier of the underlying type of t.
+func underlyingID(t *itype) string {
+	for t.cat == aliasT {
+		t = t.val
+	}
+	switch t.cat {
+	case valueT:
+		if k := t.rtype.Kind(); k <= reflect.Complex128 || k == reflect.String {
+			return k.String()
+		}
+		return t.rtype.String()
+	case arrayT, chanT, chanRecvT, chanSendT, funcT, interfaceT, mapT, ptrT, structT:
+		u := *t
+		u.name, u.path = "", ""
+		return u.id()
+	}
+	return t.id()
+}
+
+// isGeneric returns true if n declares a generic function, method or type.
+func isGeneric(n *node) bool {
+	switch n.kind {
+	case funcDecl:
+		if len(n.child) > 4 {
+			return true
+		}
+		if len(n.child[0].child) > 0 {
+			t := n.child[0].child[0].lastChild()
+			if t.kind == starExpr {
+				t = t.child[0]
+			}
+			return t.kind == indexExpr
+		}
+	case typeSpec:
+		return len(n.child) > 2
+	}
+	return false
+}
+
+// genericUse returns an error if the identifier or selector n refers to the
+// generic t without instantiating it.
+func genericUse(n *node, t *itype) error {
+	if t == nil || t.cat != genericT {
+		return nil
+	}
+	if a := n.anc; (a.kind == indexExpr || a.kind == callExpr) && a.child[0] == n {
+		return nil
+	}
+	if t.node != nil && t.node.kind == funcDecl {
+		return n.cfgErrorf("cannot use generic function %s without instantiation", t.name)
+	}
+	return n.cfgErrorf("cannot use generic type %s without instantiation", t.name)
+}
+
+// constraintUse returns an error if the identifier n refers to the constraint
+// interface t outside of a constraint.
+func constraintUse(n *node, t *itype) error {
+	if t == nil || t.constraint != nil || t.terms == nil && !requiresComparable(t) {
+		return nil
+	}
+	for a := n.anc; a != nil; a = a.anc {
+		switch {
+		case a.kind == interfaceType:
+			return nil
+		case a.kind == fieldList && a.anc != nil && (a.anc.kind == typeSpec || a.anc.kind == funcDecl && a.anc.child[0] != a):
+			// Type parameter list.
+			return nil
+		}
+	}
+	if t.terms != nil {
+		return n.cfgErrorf("cannot use type %s outside a type constraint: interface contains type constraints", n.ident)
+	}
+	return n.cfgErrorf("cannot use type %s outside a type constraint: interface is (or embeds) comparable", n.ident)
+}
+
+// typeParamList returns the names and constraint nodes of the type parameter
+// list n.
+func typeParamList(n *node) (names []string, cons []*node) {
+	for _, f := range n.child {
+		l := len(f.child) - 1
+		for _, c := range f.child[:l] {
+			names = append(names, c.ident)
+			cons = append(cons, f.child[l])
+		}
+	}
+	return names, cons
+}
+
+// typeArgs returns the types of the type argument nodes, and true if one of
+// them is not complete yet.
+func typeArgs(interp *Interpreter, sc *scope, nodes []*node) ([]*itype, bool, error) {
+	types := make([]*itype, len(nodes))
+	incomplete := false
+	for i, c := range nodes {
+		t, err := nodeType(interp, sc, c)
+		if err != nil {
+			return nil, false, err
+		}
+		types[i] = t
+		incomplete = incomplete || t.incomplete
+	}
+	return types, incomplete, nil
+}
+
+// declareGeneric registers in scope sc the generic function, method or type
+// declared by n, in package path.
+func (interp *Interpreter) declareGeneric(sc *scope, n *node, path string) error {
+	switch {
+	case n.kind == typeSpec:
+		name := n.child[0].ident
+		if sym := sc.sym[name]; sym != nil && sym.typ != nil && sym.typ.cat == genericT && sym.typ.node == nil {
+			// Methods are already declared.
+			sym.typ.node, sym.node = n, n
+			return nil
+		}
+		sc.sym[name] = &symbol{kind: typeSym, typ: &itype{cat: genericT, name: name, path: path, node: n, scope: sc.pushBloc()}, node: n}
+
+	case isMethod(n):
+		rtn := n.child[0].child[0].lastChild()
+		if rtn.kind == starExpr {
+			rtn = rtn.child[0]
+		}
+		name := rtn.child[0].ident
+		sym := sc.sym[name]
+		if sym == nil {
+			// Add type if necessary, so method can be registered.
+			sym = &symbol{kind: typeSym, typ: &itype{cat: genericT, name: name, path: path, scope: sc.pushBloc()}}
+			sc.sym[name] = sym
+		}
+		g := sym.typ
+		if g.cat != genericT {
+			return rtn.cfgErrorf("%s is not a generic type", name)
+		}
+		n.ident = n.child[1].ident
+		g.method = append(g.method, n)
+		for _, s := range g.scope.sym {
+			if err := interp.instantiateMethod(g, s, n); err != nil {
+				return err
+			}
+		}
+
+	default:
+		name := n.child[1].ident
+		sc.sym[name] = &symbol{kind: funcSym, typ: &itype{cat: genericT, name: name, path: path, node: n, scope: sc.pushBloc()}, node: n, index: -1}
+	}
+	return nil
+}
+
+// clone returns a deep copy of the subtree n, attached to anc. Unlike dup,
+// source positions are preserved, so errors in instances are reported at
+// the generic declaration.
+func (interp *Interpreter) clone(n, anc *node) *node {
+	c := *n
+	c.index = atomic.AddInt64(&interp.nindex, 1)
+	c.anc = anc
+	c.start = &c
+	c.child = nil
+	for _, cc := range n.child {
+		c.child = append(c.child, interp.clone(cc, &c))
+	}
+	return &c
+}
+
+// typeArgNode returns the node of the i-th explicit type argument of the
+// instantiation n, or n if the type argument is inferred.
+func typeArgNode(n *node, i int) *node {
+	a := n
+	if a.kind == callExpr {
+		a = a.child[0]
+	}
+	if a.kind == indexExpr && i+1 < len(a.child) {
+		return a.child[i+1]
+	}
+	return n
+}
+
+// instantiate returns the symbol of the instance of the generic function or
+// type g for the type arguments types, created on first use. Node n is the
+// instantiation expression.
+func (interp *Interpreter) instantiate(g *itype, types []*itype, n *node) (*symbol, error) {
+	if g.node == nil {
+		return nil, n.cfgErrorf("undefined: %s", g.name)
+	}
+	names, _ := typeParamList(g.node.lastChild())
+	switch {
+	case len(types) > len(names):
+		return nil, n.cfgErrorf("got %d type arguments but %s has %d type parameters", len(types), g.name, len(names))
+	case len(types) < len(names) && g.node.kind == typeSpec:
+		return nil, n.cfgErrorf("not enough type arguments for type %s: have %d, want %d", g.name, len(types), len(names))
+	case len(types) < len(names):
+		return nil, n.cfgErrorf("cannot infer %s", names[len(types)])
+	}
+	ids := make([]string, len(types))
+	for i, t := range types {
+		ids[i] = typeKey(t)
+	}
+	key := g.name + "[" + strings.Join(ids, ",") + "]"
+	if sym := g.scope.sym[key]; sym != nil {
+		return sym, interp.instanceType(sym)
+	}
+
+	inst := interp.clone(g.node, g.node.anc)
+	tparams := inst.lastChild()
+	inst.child = inst.child[:len(inst.child)-1]
+	_, cons := typeParamList(tparams)
+	sc := g.scope.pushBloc()
+	for i, name := range names {
+		if name != "_" {
+			sc.sym[name] = &symbol{kind: typeSym, typ: types[i]}
+		}
+	}
+	for i, c := range cons {
+		ct, err := constraintType(interp, sc, c)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkConstraint(typeArgNode(n, i), types[i], ct, constraintName(c, ct)); err != nil {
+			return nil, err
+		}
+	}
+
+	sym := &symbol{node: inst}
+	if inst.kind == funcDecl {
+		inst.child[1].ident = key
+		sym.kind, sym.index = funcSym, -1
+		sym.typ = &itype{name: key, incomplete: true, node: inst.child[2], scope: sc}
+		g.scope.sym[key] = sym
+		interp.instances = append(interp.instances, instance{node: inst, sc: sc, gen: g, key: key, sym: sym})
+		return sym, interp.instanceType(sym)
+	}
+
+	// The instance type is declared in the instance scope, to resolve recursive references.
+	inst.child[0].ident = key
+	sym.kind = typeSym
+	sym.typ = &itype{name: key, path: g.path, incomplete: true, node: inst.child[1], scope: sc}
+	g.scope.sym[key] = sym
+	sc.sym[key] = sym
+	if err := interp.instanceType(sym); err != nil {
+		delete(g.scope.sym, key)
+		return nil, err
+	}
+	for _, m := range g.method {
+		if err := interp.instantiateMethod(g, sym, m); err != nil {
+			return nil, err
+		}
+	}
+	return sym, nil
+}
+
+// instanceType computes the type of the instance sym, if not complete yet.
+func (interp *Interpreter) instanceType(sym *symbol) error {
+	t := sym.typ
+	if !t.incomplete {
+		return nil
+	}
+	typ, err := nodeType(interp, t.scope, t.node)
+	if err != nil {
+		return err
+	}
+	if sym.kind == funcSym {
+		sym.typ, sym.node.typ = typ, typ
+		return nil
+	}
+	switch {
+	case t.node.kind == identExpr:
+		typ = &itype{cat: aliasT, val: typ, field: typ.field, incomplete: typ.incomplete}
+	case typ.name != t.name:
+		// Do not rename a type defined elsewhere.
+		u := *typ
+		typ = &u
+	}
+	typ.name, typ.path, typ.method, typ.node, typ.scope = t.name, t.path, t.method, t.node, t.scope
+	sym.typ, sym.node.typ = typ, typ
+	return nil
+}
+
+// instantiateMethod adds to the instance type sym the instance of the method
+// m of the generic type g.
+func (interp *Interpreter) instantiateMethod(g *itype, sym *symbol, m *node) error {
+	t := sym.typ
+	for _, tm := range t.method {
+		if tm.ident == m.ident {
+			return nil
+		}
+	}
+	names, _ := typeParamList(g.node.lastChild())
+	mi := interp.clone(m, m.anc)
+	recv := mi.child[0].child[0].lastChild()
+	rtn := recv
+	if rtn.kind == starExpr {
+		rtn = rtn.child[0]
+	}
+	sc := g.scope.pushBloc()
+	for i, c := range rtn.child[1:] {
+		if i < len(names) && c.ident != "_" {
+			sc.sym[c.ident] = t.scope.sym[names[i]]
+		}
+	}
+	// Refer to the instance type by its name.
+	rtn.kind, rtn.action, rtn.ident, rtn.child = identExpr, aNop, t.name, nil
+	sc.sym[t.name] = sym
+	typ, err := nodeType(interp, sc, mi.child[2])
+	if err != nil {
+		return err
+	}
+	mi.ident, mi.typ = m.ident, typ
+	rtn.typ = t
+	if recv != rtn {
+		recv.typ = &itype{cat: ptrT, val: t, incomplete: t.incomplete, node: recv, scope: sc}
+	}
+	t.method = append(t.method, mi)
+	interp.instances = append(interp.instances, instance{node: mi, sc: sc, gen: g, key: t.name})
+	return nil
+}
+
+// callInstance returns the symbol of the instance of the generic function
+// called by n, with type arguments explicit or inferred from the call
+// arguments. The symbol is nil if argument types are not known yet.
+func (interp *Interpreter) callInstance(sc *scope, n *node) (*symbol, error) {
+	fn := n.child[0]
+	var types []*itype
+	if fn.kind == indexExpr {
+		var incomplete bool
+		var err error
+		if types, incomplete, err = typeArgs(interp, sc, fn.child[1:]); err != nil || incomplete {
+			return nil, err
+		}
+		fn = fn.child[0]
+	}
+	g, err := nodeType(interp, sc, fn)
+	if err != nil {
+		return nil, err
+	}
+	if g.node == nil || g.node.kind != funcDecl {
+		return nil, fn.cfgErrorf("cannot use generic type %s without instantiation", g.name)
+	}
+	args, incomplete, err := typeArgs(interp, sc, n.child[1:])
+	if err != nil || incomplete {
+		return nil, err
+	}
+	if len(n.child) == 2 && isCall(n.child[1]) && !interp.isBuiltinCall(n.child[1]) {
+		// A multiple value call expression is expanded into arguments.
+		ft, err := nodeType(interp, sc, n.child[1].child[0])
+		if err != nil {
+			return nil, err
+		}
+		if ft.numOut() > 1 {
+			args = args[:0]
+			for i := 0; i < ft.numOut(); i++ {
+				args = append(args, ft.out(i))
+			}
+		}
+	}
+	if types, err = interp.inferTypes(g, types, args, n); err != nil {
+		return nil, err
+	}
+	return interp.instantiate(g, types, n)
+}
+
+// inference holds the state of the type arguments inference of a generic function.
+type inference struct {
+	interp *Interpreter
+	sc     *scope   // scope of the generic declaration
+	names  []string // type parameter names
+	types  []*itype // inferred type arguments, or nil
+}
+
+// inferTypes returns the type arguments of the call n of the generic function
+// g, from the explicit type arguments types and the types of call arguments args.
+func (interp *Interpreter) inferTypes(g *itype, types, args []*itype, n *node) ([]*itype, error) {
+	names, cons := typeParamList(g.node.lastChild())
+	if len(types) >= len(names) {
+		return types, nil
+	}
+	u := &inference{interp: interp, sc: g.scope, names: names, types: make([]*itype, len(names))}
+	copy(u.types, types)
+	params := fieldTypeNodes(g.node.child[2].child[0])
+	variadic := len(params) > 0 && params[len(params)-1].kind == ellipsisExpr
+	param := func(i int) *node {
+		switch l := len(params) - 1; {
+		case variadic && i >= l && n.action == aCallSlice:
+			return params[l]
+		case variadic && i >= l:
+			return params[l].child[0]
+		case i <= l:
+			return params[i]
+		}
+		return nil
+	}
+
+	// Typed arguments first, then constraint core types, then untyped constants.
+	for i, a := range args {
+		if p := param(i); p != nil && !a.untyped {
+			u.unify(p, a)
+		}
+	}
+	u.core(cons)
+	defaults := make([]*itype, len(names))
+	for i, a := range args {
+		p := param(i)
+		if p == nil || !a.untyped || p.kind != identExpr {
+			continue
+		}
+		if j := u.index(p.ident); j >= 0 && u.types[j] == nil && (defaults[j] == nil || untypedRank(a) > untypedRank(defaults[j])) {
+			defaults[j] = a
+		}
+	}
+	for i, d := range defaults {
+		if d != nil {
+			u.types[i] = interp.universe.sym[d.name].typ
+		}
+	}
+	u.core(cons)
+
+	for i, t := range u.types {
+		if t == nil {
+			return nil, n.cfgErrorf("in call to %s, cannot infer %s", g.name, names[i])
+		}
+	}
+	return u.types, nil
+}
+
+// untypedRank orders the kinds of untyped constants, to get the default type
+// of a mix of them.
+func untypedRank(t *itype) int {
+	switch t.cat {
+	case int32T:
+		return 1
+	case float64T:
+		return 2
+	case complex128T:
+		return 3
+	}
+	return 0
+}
+
+// fieldTypeNodes returns the type node of each field of the field list n.
+func fieldTypeNodes(n *node) (nodes []*node) {
+	for _, f := range n.child {
+		l := len(f.child) - 1
+		nodes = append(nodes, f.child[l])
+		for i := 1; i < l; i++ {
+			nodes = append(nodes, f.child[l])
+		}
+	}
+	return nodes
+}
+
+func (u *inference) index(name string) int {
+	for i, n := range u.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// unify binds the type parameters found in the type expression p, to match
+// the corresponding parts of type t.
+func (u *inference) unify(p *node, t *itype) {
+	if t == nil || t.incomplete || t.untyped || t.cat == nilT {
+		return
+	}
+	if p.kind == identExpr {
+		if i := u.index(p.ident); i >= 0 && u.types[i] == nil {
+			u.types[i] = u.normalize(t)
+		}
+		return
+	}
+	if p.kind == parenExpr {
+		u.unify(p.child[0], t)
+		return
+	}
+	if p.kind == indexExpr {
+		u.unifyInstance(p, t)
+		return
+	}
+	for t.cat == aliasT {
+		t = t.val
+	}
+	switch k := t.TypeOf().Kind(); {
+	case p.kind == starExpr && k == reflect.Ptr,
+		p.kind == ellipsisExpr && k == reflect.Slice,
+		p.kind == arrayType && (k == reflect.Slice || k == reflect.Array),
+		(p.kind == chanType || p.kind == chanTypeRecv || p.kind == chanTypeSend) && k == reflect.Chan:
+		u.unify(p.lastChild(), elemType(t))
+	case p.kind == mapType && k == reflect.Map:
+		if t.cat == valueT {
+			u.unify(p.child[0], &itype{cat: valueT, rtype: t.rtype.Key()})
+		} else {
+			u.unify(p.child[0], t.key)
+		}
+		u.unify(p.child[1], elemType(t))
+	case p.kind == funcType && k == reflect.Func:
+		for i, c := range fieldTypeNodes(p.child[0]) {
+			if i < t.numIn() {
+				u.unify(c, t.in(i))
+			}
+		}
+		if len(p.child) == 2 {
+			for i, c := range fieldTypeNodes(p.child[1]) {
+				if i < t.numOut() {
+					u.unify(c, t.out(i))
+				}
+			}
+		}
+	}
+}
+
+// unifyInstance unifies the instantiation p of a generic type with type t.
+func (u *inference) unifyInstance(p *node, t *itype) {
+	if p.child[0].kind != identExpr {
+		return
+	}
+	s, _, ok := u.sc.lookup(p.child[0].ident)
+	if !ok || s.typ == nil || s.typ.cat != genericT || s.typ.node == nil {
+		return
+	}
+	g := s.typ
+	if is := g.scope.sym[t.name]; is == nil || is.typ != t {
+		return
+	}
+	names, _ := typeParamList(g.node.lastChild())
+	for i, c := range p.child[1:] {
+		if i < len(names) {
+			if b := t.scope.sym[names[i]]; b != nil {
+				u.unify(c, b.typ)
+			}
+		}
+	}
+}
+
+// core unifies the type arguments with the core type of their constraint cons,
+// if any.
+func (u *inference) core(cons []*node) {
+	for i, c := range cons {
+		if u.types[i] == nil {
+			continue
+		}
+		for c.kind == interfaceType && len(c.child[0].child) == 1 && len(c.child[0].child[0].child) == 1 {
+			c = c.child[0].child[0].child[0]
+		}
+		switch c.kind {
+		case unaryExpr:
+			if c.action == aTilde {
+				u.unify(c.child[0], u.types[i])
+			}
+		case arrayType, chanType, chanTypeRecv, chanTypeSend, funcType, mapType, starExpr:
+			u.unify(c, u.types[i])
+		}
+	}
+}
+
+// normalize returns the interpreter type of t, where binary predeclared types
+// are replaced by their equivalent universe types.
+func (u *inference) normalize(t *itype) *itype {
+	if t.cat == valueT && t.rtype.PkgPath() == "" && t.rtype.Name() != "" {
+		if s := u.interp.universe.sym[t.rtype.Name()]; s != nil && s.kind == typeSym {
+			return s.typ
+		}
+	}
+	return t
+}
+
+// elemType returns the element type of the pointer, array, slice, chan or map type t.
+func elemType(t *itype) *itype {
+	if t.cat == valueT {
+		return &itype{cat: valueT, rtype: t.rtype.Elem()}
+	}
+	return t.val
+}
+
+// checkGeneric checks the generic function or type g by instantiating it with
+// type parameters bound to the types of their constraint. There is one
+// instance per type of the largest type set.
+func (interp *Interpreter) checkGeneric(g *itype) error {
+	for k, n := 0, 1; k < n; k++ {
+		types, size, err := interp.typeParamTypes(g, k)
+		if err != nil {
+			return err
+		}
+		if size > n {
+			n = size
+		}
+		if _, err := interp.instantiate(g, types, g.node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeParamTypes returns the types standing for the type parameters of the
+// generic g, when checking its declaration, and the size of the largest
+// constraint type set. A type parameter stands for the k-th type of its
+// constraint type set, modulo its size, or for its constraint interface.
+func (interp *Interpreter) typeParamTypes(g *itype, k int) ([]*itype, int, error) {
+	names, cons := typeParamList(g.node.lastChild())
+	sc := g.scope.pushBloc()
+	types := make([]*itype, len(names))
+	size := 0
+	bind := func(i int) error {
+		ct, err := constraintType(interp, sc, cons[i])
+		if err != nil {
+			return err
+		}
+		t := &itype{cat: interfaceT}
+		switch {
+		case len(ct.terms) > 0 && len(ct.methods()) == 0:
+			u := *ct.terms[k%len(ct.terms)].typ
+			t = &u
+			if len(ct.terms) > size {
+				size = len(ct.terms)
+			}
+		case isInterface(ct):
+			u := *ct
+			t = &u
+		}
+		t.name, t.path, t.method, t.constraint = names[i], "", nil, ct
+		sc.sym[names[i]] = &symbol{kind: typeSym, typ: t}
+		types[i] = t
+		return nil
+	}
+	for done := 0; done < len(names); {
+		progress := false
+		for i, c := range cons {
+			if types[i] != nil || refersTo(c, names, types) {
+				continue
+			}
+			if err := bind(i); err != nil {
+				return nil, 0, err
+			}
+			progress = true
+			done++
+		}
+		if progress {
+			continue
+		}
+		// Constraints refer to each other: bind the remaining type parameters
+		// to the empty interface before computing their constraints.
+		for i, name := range names {
+			if types[i] == nil {
+				sc.sym[name] = &symbol{kind: typeSym, typ: &itype{cat: interfaceT, name: name, constraint: &itype{cat: interfaceT}}}
+			}
+		}
+		for i := range names {
+			if types[i] == nil {
+				if err := bind(i); err != nil {
+					return nil, 0, err
+				}
+				done++
+			}
+		}
+	}
+	return types, size, nil
+}
+
+// refersTo returns true if the type expression n refers to one of the type
+// parameters names whose type is not set yet.
+func refersTo(n *node, names []string, types []*itype) bool {
+	if n.kind == identExpr {
+		for i, name := range names {
+			if name == n.ident && types[i] == nil {
+				return true
+			}
+		}
+	}
+	for _, c := range n.child {
+		if refersTo(c, names, types) {
+			return true
+		}
+	}
+	return false
+}
+
+// cfgInstances compiles the instances of generics created since the
+// instances queue had length from, including those created by the compilation
+// itself.
+func (interp *Interpreter) cfgInstances(from int) error {
+	var nodes []*node
+	for len(interp.instances) > from {
+		inst := interp.instances[from]
+		if inst.sym != nil {
+			if err := interp.instanceType(inst.sym); err != nil {
+				return err
+			}
+		}
+		if _, err := interp.cfgScope(inst.node, inst.sc); err != nil {
+			return err
+		}
+		interp.instances = append(interp.instances[:from], interp.instances[from+1:]...)
+		nodes = append(nodes, inst.node)
+	}
+	for _, n := range nodes {
+		if err := genRun(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropInstances discards the instances of generics queued since the instances
+// queue had length from, following a compilation error.
+func (interp *Interpreter) dropInstances(from int) {
+	if len(interp.instances) <= from {
+		return
+	}
+	for _, inst := range interp.instances[from:] {
+		delete(inst.gen.scope.sym, inst.key)
+	}
+	interp.instances = interp.instances[:from]
+}
@@ -5,6 +5,14 @@ import (
 	"reflect"
 )
 
+// genericPackages are the standard packages whose exported functions are
+// generic, thus without a reflect representation usable by the interpreter.
+var genericPackages = map[string]bool{
+	"iter":   true,
+	"maps":   true,
+	"slices": true,
+}
+
 // gta performs a global types analysis on the AST, registering types,
 // variables and functions symbols at package level, prior to CFG.
 // All function bodies are skipped. GTA is necessary to handle out of
@@ -54,6 +62,12 @@ func (interp *Interpreter) gta(root *node, rpath, importPath string) ([]*node, e
 				val := reflect.ValueOf(sc.iota)
 				if n.anc.kind == constDecl {
 					if _, err2 := interp.cfg(n, importPath); err2 != nil {
+						if !hasUndefinedIdent(sc, n) {
+							// All dependencies are known: the error is definitive,
+							// e.g. a constant overflow.
+							err = err2
+							return false
+						}
 						// Constant value can not be computed yet.
 						// Come back when child dependencies are known.
 						revisit = append(revisit, n)
@@ -84,16 +98,28 @@ func (interp *Interpreter) gta(root *node, rpath, importPath string) ([]*node, e
 				}
 				if n.anc.kind == constDecl {
 					sc.sym[dest.ident].kind = constSym
-					if childPos(n) == len(n.anc.child)-1 {
-						sc.iota = 0
-					} else {
-						sc.iota++
-					}
+				}
+			}
+			if n.anc.kind == constDecl {
+				// iota is incremented once per ConstSpec.
+				if childPos(n) == len(n.anc.child)-1 {
+					sc.iota = 0
+				} else {
+					sc.iota++
 				}
 			}
 			return false
 
 		case defineXStmt:
+			src := n.lastChild()
+			if src.kind == typeAssertExpr {
+				src = src.child[1]
+			}
+			if typ, err2 := nodeType(interp, sc, src); err2 != nil || !typ.isComplete() {
+				// Come back when type is known.
+				revisit = append(revisit, n)
+				return false
+			}
 			err = compDefineX(sc, n)
 
 		case valueSpec:
@@ -127,6 +153,11 @@ func (interp *Interpreter) gta(root *node, rpath, importPath string) ([]*node, e
 			}
 
 		case funcDecl:
+			if isGeneric(n) {
+				// Generic functions and methods are compiled at instantiation.
+				err = interp.declareGeneric(sc, n, rpath)
+				return false
+			}
 			if n.typ, err = nodeType(interp, sc, n.child[2]); err != nil {
 				return false
 			}
@@ -185,6 +216,19 @@ func (interp *Interpreter) gta(root *node, rpath, importPath string) ([]*node, e
 			} else {
 				ipath = constToString(n.child[0].rval)
 			}
+			path := ipath
+			if ipath, err = interp.hooks.importPath(path); err != nil {
+				err = n.cfgErrorf("import %q denied: %v", path, err)
+				return false
+			}
+			if name == "_" && ipath == "embed" && interp.binPkg[ipath] == nil {
+				// Required by go:embed directives, which are processed at parsing.
+				return false
+			}
+			if interp.binPkg[ipath] != nil && !interp.isAllowed(ipath) {
+				err = n.cfgErrorf("import %q denied: package not allowed", path)
+				return false
+			}
 			// Try to import a binary package first, or a source package
 			var pkgName string
 			if interp.binPkg[ipath] != nil {
@@ -240,11 +284,18 @@ func (interp *Interpreter) gta(root *node, rpath, importPath string) ([]*node, e
 					err = n.cfgErrorf("%s redeclared as imported package name", name)
 					return false
 				}
+			} else if genericPackages[ipath] {
+				err = n.cfgErrorf("import %q error: generic functions of binary packages are not supported", ipath)
 			} else {
 				err = n.cfgErrorf("import %q error: %v", ipath, err)
 			}
 
 		case typeSpec:
+			if isGeneric(n) {
+				// Generic types are defined at instantiation.
+				err = interp.declareGeneric(sc, n, rpath)
+				return false
+			}
 			typeName := n.child[0].ident
 			var typ *itype
 			if typ, err = nodeType(interp, sc, n.child[1]); err != nil {
@@ -255,6 +306,12 @@ func (interp *Interpreter) gta(root *node, rpath, importPath string) ([]*node, e
 				n.typ = &itype{cat: aliasT, val: typ, name: typeName, path: rpath, field: typ.field, incomplete: typ.incomplete, scope: sc, node: n.child[0]}
 				copy(n.typ.method, typ.method)
 			} else {
+				if n.child[1].kind == indexExpr {
+					// Do not rename the instance of a generic type.
+					t := *typ
+					t.method = nil
+					typ = &t
+				}
 				n.typ = typ
 				n.typ.name = typeName
 				n.typ.path = rpath
@@ -294,8 +351,37 @@ func (interp *Interpreter) gta(root *node, rpath, importPath string) ([]*node, e
 	return revisit, err
 }
 
+// hasUndefinedIdent returns true if an identifier used in n, excluding
+// selected fields or methods and defined names, is not yet known in scope sc.
+func hasUndefinedIdent(sc *scope, n *node) bool {
+	found := false
+	var visit func(c *node) bool
+	visit = func(c *node) bool {
+		switch {
+		case found:
+			return false
+		case c.kind == selectorExpr:
+			// The selected name is not in scope, only check the operand.
+			c.child[0].Walk(visit, nil)
+			return false
+		case c.kind == identExpr && c.ident != "_" && !(c.anc == n && childPos(c) < n.nleft):
+			_, _, ok := sc.lookup(c.ident)
+			found = !ok
+		}
+		return true
+	}
+	n.Walk(visit, nil)
+	return found
+}
+
 // gtaRetry (re)applies gta until all global constants and types are defined.
 func (interp *Interpreter) gtaRetry(nodes []*node, importPath string) error {
+	// Global types analysis and CFG may change scopes, even on failure, and
+	// all compilations start here: previously compiled sources are now stale.
+	interp.mutex.Lock()
+	interp.generation++
+	interp.mutex.Unlock()
+
 	revisit := []*node{}
 	for {
 		for _, n := range nodes {
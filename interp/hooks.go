@@ -7,15 +7,33 @@ const hooksPath = "github.com/containous/yaegi"
 // convertFn is the signature of a symbol converter.
 type convertFn func(from, to reflect.Type) func(src, dest reflect.Value)
 
+// importFn is the signature of an import path resolver.
+type importFn func(importPath string) (string, error)
+
 // hooks are external symbol bindings.
 type hooks struct {
-	convert []convertFn
+	convert       []convertFn
+	resolveImport importFn
 }
 
 func (h *hooks) Parse(m map[string]reflect.Value) {
 	if con, ok := getConvertFn(m["convert"]); ok {
 		h.convert = append(h.convert, con)
 	}
+	if v := m["resolveImport"]; v.IsValid() {
+		if fn, ok := v.Interface().(func(string) (string, error)); ok {
+			h.resolveImport = fn
+		}
+	}
+}
+
+// importPath returns the import path to use in place of path, or an error
+// if the import of path is denied.
+func (h *hooks) importPath(path string) (string, error) {
+	if h.resolveImport == nil {
+		return path, nil
+	}
+	return h.resolveImport(path)
 }
 
 func getConvertFn(v reflect.Value) (convertFn, bool) {
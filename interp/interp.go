@@ -3,6 +3,7 @@ package interp
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
 	"errors"
 	"flag"
 	"fmt"
@@ -12,15 +13,19 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 )
 
 // Interpreter node structure for AST and CFG.
@@ -72,6 +77,13 @@ type frame struct {
 	deferred  [][]reflect.Value  // defer stack
 	recovered interface{}        // to handle panic recover
 	done      reflect.SelectCase // for cancellation of channel operations
+
+	// Interpreted call stack, for debug.
+	def    *node        // function definition node, or nil
+	caller *frame       // calling frame, or nil
+	pos    *node        // call position in calling frame, or nil
+	stack  []stackEntry // call stack captured at panic, or nil
+	depth  int          // number of interpreted calls in the call stack
 }
 
 func newFrame(anc *frame, len int, id uint64) *frame {
@@ -98,6 +110,24 @@ func (f *frame) clone() *frame {
 		recovered: f.recovered,
 		id:        f.runid(),
 		done:      f.done,
+		def:       f.def,
+		caller:    f.caller,
+		pos:       f.pos,
+		depth:     f.depth,
+	}
+}
+
+// renew moves the values at indexes to new locations. Closures capturing
+// them have their own copy of the frame data, see getClosure, and keep the
+// previous locations, which gives each loop iteration its own copy of the
+// loop variables.
+func (f *frame) renew(indexes []int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, i := range indexes {
+		v := reflect.New(f.data[i].Type()).Elem()
+		v.Set(f.data[i])
+		f.data[i] = v
 	}
 }
 
@@ -113,13 +143,22 @@ type opt struct {
 	cfgDot bool // display CFG graph (debug)
 	// dotCmd is the command to process the dot graph produced when astDot and/or
 	// cfgDot is enabled. It defaults to 'dot -Tdot -o <filename>.dot'.
-	dotCmd   string
-	noRun    bool          // compile, but do not run
-	fastChan bool          // disable cancellable chan operations
-	context  build.Context // build context: GOPATH, build constraints
-	stdin    io.Reader     // standard input
-	stdout   io.Writer     // standard output
-	stderr   io.Writer     // standard error
+	dotCmd       string
+	noRun        bool              // compile, but do not run
+	fastChan     bool              // disable cancellable chan operations
+	compileCache bool              // reuse compiled code of identical sources
+	sortedMaps   bool              // range over maps in sorted key order
+	maxCallDepth int               // maximum depth of interpreted calls, 0 for no limit
+	maxSteps     uint64            // maximum number of executed nodes per evaluation, 0 for no limit
+	maxErrors    int               // maximum number of reported compilation errors
+	context      build.Context     // build context: GOPATH, build constraints
+	stdin        io.Reader         // standard input
+	stdout       io.Writer         // standard output
+	stderr       io.Writer         // standard error
+	env          map[string]string // sandboxed environment, nil for the process environment
+	dir          string            // working directory, empty for the process one
+	// onResult is called with the result of each evaluation in REPL.
+	onResult func(src string, v reflect.Value, err error)
 }
 
 // Interpreter contains global resources and state.
@@ -130,6 +169,10 @@ type Interpreter struct {
 	// architectures.
 	id uint64
 
+	// steps is the number of CFG nodes executed in the current evaluation,
+	// only accessed atomically, if maxSteps is set.
+	steps uint64
+
 	name string // name of the input source file (or main)
 
 	opt                        // user settable options
@@ -137,7 +180,9 @@ type Interpreter struct {
 	nindex     int64           // next node index
 	fset       *token.FileSet  // fileset to locate node in source code
 	binPkg     Exports         // binary packages used in interpreter, indexed by path
+	stdioSyms  Exports         // binary symbols replaced by fixStdio, indexed by path
 	rdir       map[string]bool // for src import cycle detection
+	instances  []instance      // instances of generics pending compilation
 
 	mutex    sync.RWMutex
 	frame    *frame            // program data storage during execution
@@ -146,10 +191,31 @@ type Interpreter struct {
 	srcPkg   imports           // source packages used in interpreter, indexed by path
 	pkgNames map[string]string // package names, indexed by import path
 	done     chan struct{}     // for cancellation of channel operations
+	allowed  map[string]bool   // importable binary packages, or nil for all
+	panicked []stackEntry      // interpreted call stack of the last unrecovered panic
+	history  []string          // sources successfully evaluated in REPL
+
+	generation uint64                 // incremented at each change of binPkg, srcPkg or scopes
+	cache      map[cacheKey]*compiled // compiled sources, if compileCache is set
 
 	hooks *hooks // symbol hooks
 }
 
+// cacheKey identifies a compiled source in the compilation cache.
+type cacheKey struct {
+	sum  [sha256.Size]byte // source hash
+	name string            // source name
+	inc  bool              // incremental mode
+	gen  uint64            // interpreter generation after compilation
+}
+
+// compiled stores the result of the compilation of a source.
+type compiled struct {
+	pkgName   string
+	root      *node
+	initNodes []*node
+}
+
 const (
 	mainID   = "main"
 	selfPath = "github.com/containous/yaegi/interp"
@@ -189,13 +255,39 @@ type Panic struct {
 
 	// Stack is the call stack buffer for debug.
 	Stack []byte
+
+	// InterpFrames is the interpreted call stack at the location of the
+	// panic, innermost first.
+	InterpFrames []Frame
+}
+
+// Frame is a location in the interpreted call stack.
+type Frame struct {
+	// Function is the name of the function, in the form used by Go stack
+	// traces, or empty for code outside of any function.
+	Function string
+
+	// File and Line are the position in source.
+	File string
+	Line int
 }
 
-// TODO: Capture interpreter stack frames also and remove
-// fmt.Println(n.cfgErrorf("panic")) in runCfg.
+// TODO: remove fmt.Fprintln(n.interp.stderr, n.cfgErrorf("panic")) in runCfg,
+// as the location of a panic is now given by Panic.InterpFrames.
 
 func (e Panic) Error() string { return fmt.Sprint(e.Value) }
 
+// ExitError is the error returned by Eval when interpreted code calls os.Exit.
+// The interpreted execution stops without running deferred functions, as in
+// Go, but the host process keeps running and can inspect the exit code.
+// Exits performed from binary code, or by system calls, can not be caught,
+// nor can a call to os.Exit from a goroutine other than the evaluating one.
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string { return fmt.Sprintf("exit status %d", e.Code) }
+
 // Walk traverses AST n in depth first order, call cbin function
 // at node entry and cbout function at node exit.
 func (n *node) Walk(in func(n *node) bool, out func(n *node)) {
@@ -212,7 +304,9 @@ func (n *node) Walk(in func(n *node) bool, out func(n *node)) {
 
 // Options are the interpreter options.
 type Options struct {
-	// GoPath sets GOPATH for the interpreter.
+	// GoPath sets GOPATH for the interpreter. As for the go command, it may
+	// be a list of directories separated by os.PathListSeparator, searched
+	// in order for source packages.
 	GoPath string
 
 	// BuildTags sets build constraints for the interpreter.
@@ -222,8 +316,56 @@ type Options struct {
 	// They default to os.Stding, os.Stdout and os.Stderr respectively.
 	Stdin          io.Reader
 	Stdout, Stderr io.Writer
+
+	// Env sets the environment variables visible to the interpreter through
+	// the os package. If nil, the process environment is used.
+	Env map[string]string
+
+	// Dir sets the working directory of the interpreted code: it is returned
+	// by os.Getwd, and relative paths given to the file functions of the os
+	// package, such as os.Open or os.Create, are resolved from it. Other
+	// packages are not affected, and absolute paths are not restricted.
+	// If empty, the process working directory is used.
+	Dir string
+
+	// ImportResolver, if not nil, is called with the path of each import.
+	// It returns the path of the package to import instead, or an error to
+	// deny the import.
+	ImportResolver func(importPath string) (string, error)
+
+	// CompileCache enables the reuse of compiled code when the same source
+	// is evaluated again, provided that no package was used and no symbol
+	// was declared in between.
+	CompileCache bool
+
+	// DeterministicMaps makes range over maps iterate in sorted key order,
+	// for reproducible outputs. Only maps with boolean, numeric or string
+	// keys can be iterated in this mode.
+	DeterministicMaps bool
+
+	// MaxCallDepth limits the depth of nested interpreted function calls.
+	// When exceeded, a recoverable panic occurs in the interpreted program.
+	// The default 0 means no limit.
+	MaxCallDepth int
+
+	// MaxSteps limits the number of execution steps of each evaluation.
+	// When exceeded, the execution is stopped and the evaluation returns
+	// ErrStepBudget. The default 0 means no limit.
+	MaxSteps uint64
+
+	// MaxErrors sets the maximum number of compilation errors reported by
+	// an evaluation, in an ErrorList if more than one. The default is 10.
+	MaxErrors int
+
+	// OnResult, if not nil, is called by REPL after the evaluation of each
+	// complete input, with its source, result value and error, whether the
+	// input produces a value or not.
+	OnResult func(src string, v reflect.Value, err error)
 }
 
+// ErrStepBudget is returned by an evaluation stopped after MaxSteps steps.
+var ErrStepBudget = errors.New("step budget exhausted")
+
 // New returns a new interpreter.
 func New(options Options) *Interpreter {
 	i := Interpreter{
@@ -236,7 +378,7 @@ func New(options Options) *Interpreter {
 		srcPkg:   imports{},
 		pkgNames: map[string]string{},
 		rdir:     map[string]bool{},
-		hooks:    &hooks{},
+		hooks:    &hooks{resolveImport: options.ImportResolver},
 	}
 
 	if i.opt.stdin = options.Stdin; i.opt.stdin == nil {
@@ -251,7 +393,28 @@ func New(options Options) *Interpreter {
 		i.opt.stderr = os.Stderr
 	}
 
+	if options.Env != nil {
+		i.opt.env = make(map[string]string, len(options.Env))
+		for k, v := range options.Env {
+			i.opt.env[k] = v
+		}
+	}
+
+	if i.opt.dir = options.Dir; i.opt.dir != "" {
+		if dir, err := filepath.Abs(i.opt.dir); err == nil {
+			i.opt.dir = dir
+		}
+	}
+
 	i.opt.context.GOPATH = options.GoPath
+	i.opt.compileCache = options.CompileCache
+	i.opt.sortedMaps = options.DeterministicMaps
+	i.opt.maxCallDepth = options.MaxCallDepth
+	i.opt.maxSteps = options.MaxSteps
+	i.opt.onResult = options.OnResult
+	if i.opt.maxErrors = options.MaxErrors; i.opt.maxErrors <= 0 {
+		i.opt.maxErrors = 10
+	}
 	if len(options.BuildTags) > 0 {
 		i.opt.context.BuildTags = options.BuildTags
 	}
@@ -275,6 +438,91 @@ func New(options Options) *Interpreter {
 	return &i
 }
 
+// Clone returns a new interpreter with the same options and binary symbols
+// as interp, but none of the source packages and state resulting from
+// previous evaluations. The symbols loaded by Use are shared read-only between
+// interp and its clones, which avoids loading them again. The standard
+// input, output and error of the clone are the ones of interp.
+func (interp *Interpreter) Clone() *Interpreter {
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+
+	h := *interp.hooks
+	h.convert = append([]convertFn(nil), h.convert...)
+	i := &Interpreter{
+		opt:      interp.opt,
+		frame:    &frame{data: []reflect.Value{}},
+		fset:     token.NewFileSet(),
+		universe: initUniverse(),
+		scopes:   map[string]*scope{},
+		binPkg:   make(Exports, len(interp.binPkg)),
+		srcPkg:   imports{},
+		pkgNames: map[string]string{},
+		rdir:     map[string]bool{},
+		allowed:  interp.allowed,
+		hooks:    &h,
+	}
+	for path, syms := range interp.binPkg {
+		i.binPkg[path] = syms
+	}
+	for path, syms := range interp.stdioSyms {
+		if i.stdioSyms == nil {
+			i.stdioSyms = Exports{}
+		}
+		i.stdioSyms[path] = make(map[string]reflect.Value, len(syms))
+		for s, sym := range syms {
+			i.stdioSyms[path][s] = sym
+		}
+	}
+
+	// Keep the binary packages preimported in REPL mode.
+	for name, sym := range interp.universe.sym {
+		if sym.kind == pkgSym && sym.typ.cat == binPkgT {
+			i.universe.sym[name] = &symbol{kind: pkgSym, typ: &itype{cat: binPkgT, path: sym.typ.path, scope: i.universe}}
+		}
+	}
+
+	// The stdio symbols are specific to each interpreter.
+	for _, path := range []string{"fmt", "flag", "log", "os"} {
+		if syms, ok := i.binPkg[path]; ok {
+			i.binPkg[path] = make(map[string]reflect.Value, len(syms))
+			for s, sym := range syms {
+				i.binPkg[path][s] = sym
+			}
+		}
+	}
+	fixStdio(i)
+	return i
+}
+
+// Reset clears the state resulting from previous evaluations: the source
+// packages, global declarations and values, and the REPL history. The options,
+// the binary symbols loaded by Use, and the binary packages preimported in
+// REPL mode are kept, so they do not need to be loaded again.
+func (interp *Interpreter) Reset() {
+	interp.mutex.Lock()
+	defer interp.mutex.Unlock()
+
+	interp.name = ""
+	atomic.StoreInt64(&interp.nindex, 0)
+	interp.rdir = map[string]bool{}
+	interp.frame = &frame{data: []reflect.Value{}}
+	interp.scopes = map[string]*scope{}
+	interp.srcPkg = imports{}
+	interp.pkgNames = map[string]string{}
+	interp.panicked = nil
+	interp.history = nil
+	interp.cache = nil
+	interp.generation++
+
+	// Remove the source packages registered in the universe.
+	for name, sym := range interp.universe.sym {
+		if sym.kind == pkgSym && sym.typ.cat == srcPkgT {
+			delete(interp.universe.sym, name)
+		}
+	}
+}
+
 const (
 	bltnAppend  = "append"
 	bltnCap     = "cap"
@@ -296,8 +544,10 @@ const (
 func initUniverse() *scope {
 	sc := &scope{global: true, sym: map[string]*symbol{
 		// predefined Go types
+		"any":         {kind: typeSym, typ: &itype{cat: interfaceT}},
 		"bool":        {kind: typeSym, typ: &itype{cat: boolT, name: "bool"}},
 		"byte":        {kind: typeSym, typ: &itype{cat: uint8T, name: "uint8"}},
+		"comparable":  {kind: typeSym, typ: &itype{cat: comparableT, name: "comparable"}},
 		"complex64":   {kind: typeSym, typ: &itype{cat: complex64T, name: "complex64"}},
 		"complex128":  {kind: typeSym, typ: &itype{cat: complex128T, name: "complex128"}},
 		"error":       {kind: typeSym, typ: &itype{cat: errorT, name: "error"}},
@@ -376,10 +626,64 @@ func (interp *Interpreter) Eval(src string) (res reflect.Value, err error) {
 	return interp.eval(src, "", true)
 }
 
+// EvalMulti evaluates Go code represented as a string, as Eval, and returns
+// all the values produced by the last statement: the results of a function
+// call, or the variables defined by a short variable declaration. The slice
+// is empty if the last statement produces no value, as an assignment.
+func (interp *Interpreter) EvalMulti(src string) ([]reflect.Value, error) {
+	root, _, err := interp.evalRoot(src, "", true)
+	if err != nil || root == nil {
+		return nil, err
+	}
+
+	var values []func(*frame) reflect.Value
+	n := root
+	for (n.kind == fileStmt || n.kind == blockStmt || n.kind == varDecl) && len(n.child) > 0 {
+		n = n.lastChild()
+	}
+	switch n.kind {
+	case exprStmt:
+		c := n.child[0]
+		switch {
+		case isCall(c) && c.findex >= 0:
+			for i := 0; i < c.child[0].typ.numOut(); i++ {
+				values = append(values, valueGenerator(c, c.findex+i))
+			}
+		case !isCall(c):
+			values = append(values, genValue(c))
+		}
+	case defineStmt, defineXStmt:
+		for _, c := range n.child[:n.nleft] {
+			values = append(values, genValue(c))
+		}
+	}
+
+	res := make([]reflect.Value, len(values))
+	interp.frame.mutex.RLock()
+	defer interp.frame.mutex.RUnlock()
+	for i, v := range values {
+		res[i] = v(interp.frame)
+		if res[i].IsValid() {
+			if n, ok := res[i].Interface().(*node); ok {
+				res[i] = genFunctionWrapper(n)(interp.frame)
+			}
+		}
+	}
+	return res, nil
+}
+
 // EvalPath evaluates Go code located at path. EvalPath returns the last result
 // computed by the interpreter, and a non nil error in case of failure.
+// If path is a directory, the package made of its Go files matching the
+// build constraints, except test files, is evaluated.
 func (interp *Interpreter) EvalPath(path string) (res reflect.Value, err error) {
-	// TODO(marc): implement eval of a directory, package and tests.
+	fi, err := os.Stat(path)
+	if err != nil {
+		return res, err
+	}
+	if fi.IsDir() {
+		return interp.evalDir(path, false)
+	}
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return res, err
@@ -387,7 +691,142 @@ func (interp *Interpreter) EvalPath(path string) (res reflect.Value, err error)
 	return interp.eval(string(b), path, false)
 }
 
+// EvalTest evaluates the package located in directory path, like EvalPath,
+// including its test files. Test files of an external test package, with
+// the "_test" suffix, are ignored. The test functions are not run, but can
+// be obtained from the interpreter afterwards, for example by EvalFunc.
+func (interp *Interpreter) EvalTest(path string) (res reflect.Value, err error) {
+	return interp.evalDir(path, true)
+}
+
+// evalDir evaluates the package made of the Go source files in directory dir,
+// and of the test files if test is true.
+func (interp *Interpreter) evalDir(dir string, test bool) (res reflect.Value, err error) {
+	defer interp.catchPanic(&err)
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return res, err
+	}
+
+	var pkgName string
+	var rootNodes []*node
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() {
+			continue
+		}
+		if test && strings.HasSuffix(name, "_test.go") {
+			// Apply the file name constraints to the test file as a regular file.
+			if skipFile(&interp.context, strings.TrimSuffix(name, "_test.go")+".go") {
+				continue
+			}
+		} else if skipFile(&interp.context, name) {
+			continue
+		}
+
+		name = filepath.Join(dir, name)
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			return res, err
+		}
+		interp.name = name
+		pname, root, err := interp.ast(string(b), name, false)
+		if err != nil {
+			return res, err
+		}
+		if root == nil || test && strings.HasSuffix(pname, "_test") {
+			continue
+		}
+		if pkgName == "" {
+			pkgName = pname
+		} else if pkgName != pname {
+			return res, fmt.Errorf("found packages %s and %s in %s", pkgName, pname, dir)
+		}
+		rootNodes = append(rootNodes, root)
+	}
+	if len(rootNodes) == 0 {
+		return res, fmt.Errorf("no buildable Go source files in %s", dir)
+	}
+
+	from := len(interp.instances)
+	defer interp.dropInstances(from)
+
+	// Perform global types analysis on all files at once, as declarations
+	// may refer to each other across files.
+	if err = interp.gtaRetry(rootNodes, pkgName); err != nil {
+		return res, err
+	}
+
+	var initNodes []*node
+	for _, root := range rootNodes {
+		nodes, err := interp.cfg(root, pkgName)
+		if err != nil {
+			return res, err
+		}
+		initNodes = append(initNodes, nodes...)
+	}
+	if err = interp.cfgInstances(from); err != nil {
+		return res, err
+	}
+
+	// Add main to list of functions to run, after all inits
+	if m := interp.main(); m != nil {
+		initNodes = append(initNodes, m)
+	}
+	interp.registerPkg(pkgName)
+
+	if interp.noRun {
+		return res, err
+	}
+
+	for _, root := range rootNodes {
+		if err = genRun(root); err != nil {
+			return res, err
+		}
+	}
+	return interp.execute(pkgName, rootNodes, initNodes)
+}
+
+// catchPanic converts a panic of the interpreter into an error stored in err.
+// It must be deferred.
+func (interp *Interpreter) catchPanic(err *error) {
+	if r := recover(); r != nil {
+		var pc [64]uintptr // 64 frames should be enough.
+		n := runtime.Callers(1, pc[:])
+		interp.mutex.Lock()
+		frames := stackFrames(interp.panicked)
+		interp.panicked = nil
+		interp.mutex.Unlock()
+		if e, ok := r.(ExitError); ok {
+			*err = e
+			return
+		}
+		*err = Panic{Value: r, Callers: pc[:n], Stack: debug.Stack(), InterpFrames: frames}
+	}
+}
+
+// registerPkg makes the package pkgName visible under a path identical
+// to its name.
+func (interp *Interpreter) registerPkg(pkgName string) {
+	interp.mutex.Lock()
+	defer interp.mutex.Unlock()
+	if interp.universe.sym[pkgName] == nil {
+		interp.generation++
+		// TODO(mpl): srcPkg is supposed to be keyed by importPath. Verify it is necessary, and implement.
+		interp.srcPkg[pkgName] = interp.scopes[pkgName].sym
+		interp.universe.sym[pkgName] = &symbol{kind: pkgSym, typ: &itype{cat: srcPkgT, path: pkgName}}
+		interp.pkgNames[pkgName] = pkgName
+	}
+}
+
 func (interp *Interpreter) eval(src, name string, inc bool) (res reflect.Value, err error) {
+	_, res, err = interp.evalRoot(src, name, inc)
+	return res, err
+}
+
+// evalRoot evaluates src as eval, and also returns its compiled root node.
+func (interp *Interpreter) evalRoot(src, name string, inc bool) (root *node, res reflect.Value, err error) {
 	if name != "" {
 		interp.name = name
 	}
@@ -395,19 +834,25 @@ func (interp *Interpreter) eval(src, name string, inc bool) (res reflect.Value,
 		interp.name = DefaultSourceName
 	}
 
-	defer func() {
-		r := recover()
-		if r != nil {
-			var pc [64]uintptr // 64 frames should be enough.
-			n := runtime.Callers(1, pc[:])
-			err = Panic{Value: r, Callers: pc[:n], Stack: debug.Stack()}
+	defer interp.catchPanic(&err)
+
+	var key cacheKey
+	if interp.compileCache {
+		key = cacheKey{sum: sha256.Sum256([]byte(src)), name: interp.name, inc: inc}
+		interp.mutex.RLock()
+		key.gen = interp.generation
+		c := interp.cache[key]
+		interp.mutex.RUnlock()
+		if c != nil {
+			res, err = interp.execute(c.pkgName, []*node{c.root}, c.initNodes)
+			return c.root, res, err
 		}
-	}()
+	}
 
 	// Parse source to AST.
 	pkgName, root, err := interp.ast(src, interp.name, inc)
 	if err != nil || root == nil {
-		return res, err
+		return root, res, err
 	}
 
 	if interp.astDot {
@@ -417,13 +862,16 @@ func (interp *Interpreter) eval(src, name string, inc bool) (res reflect.Value,
 		}
 		root.astDot(dotWriter(dotCmd), interp.name)
 		if interp.noRun {
-			return res, err
+			return root, res, err
 		}
 	}
 
+	from := len(interp.instances)
+	defer interp.dropInstances(from)
+
 	// Perform global types analysis.
 	if err = interp.gtaRetry([]*node{root}, pkgName); err != nil {
-		return res, err
+		return root, res, err
 	}
 
 	// Annotate AST with CFG infos
@@ -436,7 +884,10 @@ func (interp *Interpreter) eval(src, name string, inc bool) (res reflect.Value,
 			}
 			root.cfgDot(dotWriter(dotCmd))
 		}
-		return res, err
+		return root, res, err
+	}
+	if err = interp.cfgInstances(from); err != nil {
+		return root, res, err
 	}
 
 	// Add main to list of functions to run, after all inits
@@ -448,15 +899,7 @@ func (interp *Interpreter) eval(src, name string, inc bool) (res reflect.Value,
 		// REPL may skip package statement
 		setExec(root.start)
 	}
-	interp.mutex.Lock()
-	if interp.universe.sym[pkgName] == nil {
-		// Make the package visible under a path identical to its name
-		// TODO(mpl): srcPkg is supposed to be keyed by importPath. Verify it is necessary, and implement.
-		interp.srcPkg[pkgName] = interp.scopes[pkgName].sym
-		interp.universe.sym[pkgName] = &symbol{kind: pkgSym, typ: &itype{cat: srcPkgT, path: pkgName}}
-		interp.pkgNames[pkgName] = pkgName
-	}
-	interp.mutex.Unlock()
+	interp.registerPkg(pkgName)
 
 	if interp.cfgDot {
 		dotCmd := interp.dotCmd
@@ -467,25 +910,46 @@ func (interp *Interpreter) eval(src, name string, inc bool) (res reflect.Value,
 	}
 
 	if interp.noRun {
-		return res, err
+		return root, res, err
 	}
 
 	// Generate node exec closures
 	if err = genRun(root); err != nil {
-		return res, err
+		return root, res, err
+	}
+
+	if interp.compileCache {
+		interp.mutex.Lock()
+		if interp.cache == nil {
+			interp.cache = map[cacheKey]*compiled{}
+		}
+		key.gen = interp.generation
+		interp.cache[key] = &compiled{pkgName: pkgName, root: root, initNodes: initNodes}
+		interp.mutex.Unlock()
 	}
 
+	res, err = interp.execute(pkgName, []*node{root}, initNodes)
+	return root, res, err
+}
+
+// execute runs the compiled code of package pkgName: the root nodes, then the
+// global variables initialization, then the init functions and main. The
+// result is the value of the last root node.
+func (interp *Interpreter) execute(pkgName string, roots, initNodes []*node) (res reflect.Value, err error) {
 	// Init interpreter execution memory frame
 	interp.frame.setrunid(interp.runid())
+	atomic.StoreUint64(&interp.steps, 0)
 	interp.frame.mutex.Lock()
 	interp.resizeFrame()
 	interp.frame.mutex.Unlock()
 
 	// Execute node closures
-	interp.run(root, nil)
+	for _, root := range roots {
+		interp.run(root, nil)
+	}
 
 	// Wire and execute global vars
-	n, err := genGlobalVars([]*node{root}, interp.scopes[pkgName])
+	n, err := genGlobalVars(roots, interp.scopes[pkgName])
 	if err != nil {
 		return res, err
 	}
@@ -494,8 +958,13 @@ func (interp *Interpreter) eval(src, name string, inc bool) (res reflect.Value,
 	for _, n := range initNodes {
 		interp.run(n, interp.frame)
 	}
-	v := genValue(root)
+	if interp.maxSteps > 0 && atomic.LoadUint64(&interp.steps) > interp.maxSteps {
+		return res, ErrStepBudget
+	}
+	v := genValue(roots[len(roots)-1])
+	interp.frame.mutex.RLock()
 	res = v(interp.frame)
+	interp.frame.mutex.RUnlock()
 
 	// If result is an interpreter node, wrap it in a runtime callable function
 	if res.IsValid() {
@@ -510,63 +979,426 @@ func (interp *Interpreter) eval(src, name string, inc bool) (res reflect.Value,
 // EvalWithContext evaluates Go code represented as a string. It returns
 // a map on current interpreted package exported symbols.
 func (interp *Interpreter) EvalWithContext(ctx context.Context, src string) (reflect.Value, error) {
+	return interp.evalWithContext(ctx, func() (reflect.Value, error) { return interp.Eval(src) })
+}
+
+// EvalPathWithContext evaluates Go code located at path, like EvalPath, and
+// stops the execution when ctx is done.
+func (interp *Interpreter) EvalPathWithContext(ctx context.Context, path string) (reflect.Value, error) {
+	if err := ctx.Err(); err != nil {
+		return reflect.Value{}, err
+	}
+	return interp.evalWithContext(ctx, func() (reflect.Value, error) { return interp.EvalPath(path) })
+}
+
+// evalWithContext calls eval in a cancellable execution, stopped when ctx is done.
+func (interp *Interpreter) evalWithContext(ctx context.Context, eval func() (reflect.Value, error)) (reflect.Value, error) {
 	var v reflect.Value
 	var err error
 
+	// Each evaluation has its own cancellation channel, so a cancelled
+	// evaluation does not affect the next ones.
+	cancel := make(chan struct{})
 	interp.mutex.Lock()
-	interp.done = make(chan struct{})
+	interp.done = cancel
 	interp.cancelChan = !interp.opt.fastChan
 	interp.mutex.Unlock()
 
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
-		v, err = interp.Eval(src)
+		v, err = eval()
 	}()
 
 	select {
 	case <-ctx.Done():
-		interp.stop()
+		interp.stop(cancel)
 		return reflect.Value{}, ctx.Err()
 	case <-done:
 	}
+
+	interp.mutex.Lock()
+	if interp.done == cancel {
+		interp.done = nil
+	}
+	interp.mutex.Unlock()
 	return v, err
 }
 
+// EvalFunc returns the interpreted function name, defined by a previous
+// evaluation, as a callable value of the type of prototype, which must be a
+// function with the same signature. The name may be qualified by a package
+// name or import path, otherwise the function is looked up in the main package.
+// Calling the returned value does not involve any further parsing or compilation.
+func (interp *Interpreter) EvalFunc(name string, prototype interface{}) (reflect.Value, error) {
+	pt := reflect.TypeOf(prototype)
+	if pt == nil || pt.Kind() != reflect.Func {
+		return reflect.Value{}, fmt.Errorf("prototype of %s is not a function: %T", name, prototype)
+	}
+
+	sym := interp.globalSymbol(name)
+	if sym == nil {
+		return reflect.Value{}, fmt.Errorf("undefined: %s", name)
+	}
+	if sym.kind != funcSym || sym.node == nil || sym.node.kind != funcDecl {
+		return reflect.Value{}, fmt.Errorf("%s is not a function", name)
+	}
+	if sym.typ.cat == genericT {
+		return reflect.Value{}, fmt.Errorf("cannot use generic function %s without instantiation", name)
+	}
+	def := sym.node
+	if err := checkSignature(def.typ.TypeOf(), pt); err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot use %s as %v: %v", name, pt, err)
+	}
+	return genFunctionWrapper(def)(interp.frame).Convert(pt), nil
+}
+
+// GetValue returns the current value of the package level variable or
+// constant name, defined by a previous evaluation. The name may be qualified
+// by a package name or import path, otherwise it is looked up in the main
+// package. Unexported names are allowed.
+func (interp *Interpreter) GetValue(name string) (reflect.Value, error) {
+	sym := interp.globalSymbol(name)
+	if sym == nil {
+		return reflect.Value{}, fmt.Errorf("undefined: %s", name)
+	}
+
+	switch sym.kind {
+	case varSym:
+		interp.frame.mutex.RLock()
+		defer interp.frame.mutex.RUnlock()
+		if sym.index < 0 || sym.index >= len(interp.frame.data) {
+			return reflect.Value{}, fmt.Errorf("%s is not initialized", name)
+		}
+		v := interp.frame.data[sym.index]
+		if vi, ok := v.Interface().(valueInterface); ok {
+			// Return the concrete value of an interpreted interface.
+			return vi.value, nil
+		}
+		return v, nil
+	case constSym:
+		n := &node{rval: sym.rval, typ: sym.typ}
+		convertConstantValue(n)
+		return n.rval, nil
+	}
+	return reflect.Value{}, fmt.Errorf("%s is not a variable or a constant", name)
+}
+
+// SetValue sets the package level variable name, defined by a previous
+// evaluation, to v. The name is resolved as in GetValue. The value must be
+// assignable to the variable type, or be a number or a string convertible to
+// it without loss, as an untyped constant would be. A nil v sets the zero value.
+func (interp *Interpreter) SetValue(name string, v interface{}) error {
+	sym := interp.globalSymbol(name)
+	if sym == nil {
+		return fmt.Errorf("undefined: %s", name)
+	}
+	if sym.kind != varSym || sym.index < 0 {
+		return fmt.Errorf("cannot assign to %s", name)
+	}
+
+	var val reflect.Value
+	if sym.typ.cat == interfaceT {
+		vi := valueInterface{}
+		if v != nil {
+			rv := reflect.ValueOf(v)
+			typ := &itype{cat: valueT, rtype: rv.Type()}
+			if !typ.implements(sym.typ) {
+				return fmt.Errorf("cannot assign to %s: type %v does not implement %s", name, rv.Type(), sym.typ.id())
+			}
+			vi = valueInterface{&node{typ: typ}, rv}
+		}
+		val = reflect.ValueOf(vi)
+	} else {
+		var err error
+		if val, err = assignableValue(v, sym.typ.frameType()); err != nil {
+			return fmt.Errorf("cannot assign to %s: %v", name, err)
+		}
+	}
+
+	interp.frame.mutex.Lock()
+	defer interp.frame.mutex.Unlock()
+	if sym.index >= len(interp.frame.data) {
+		// The variable is declared but the frame has not been allocated yet.
+		interp.resizeFrame()
+	}
+	interp.frame.data[sym.index].Set(val)
+	return nil
+}
+
+// assignableValue returns v as a value of type t, applying the conversions
+// allowed for untyped constants.
+func assignableValue(v interface{}, t reflect.Type) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Zero(t), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(t) {
+		return rv, nil
+	}
+	if !isConstKind(rv.Kind()) || !isConstKind(t.Kind()) || !rv.Type().ConvertibleTo(t) ||
+		(rv.Kind() == reflect.String) != (t.Kind() == reflect.String) {
+		return reflect.Value{}, fmt.Errorf("value of type %v is not assignable to type %v", rv.Type(), t)
+	}
+	if overflows(rv, t) {
+		return reflect.Value{}, fmt.Errorf("%v overflows or truncates to type %v", v, t)
+	}
+	return rv.Convert(t), nil
+}
+
+// overflows returns true if the numeric value v can not be represented
+// exactly by a value of type t.
+func overflows(v reflect.Value, t reflect.Type) bool {
+	z := reflect.Zero(t)
+	vt := v.Type()
+	switch {
+	case isComplex(t):
+		return z.OverflowComplex(v.Complex())
+	case isFloat(t):
+		switch {
+		case isFloat(vt):
+			return z.OverflowFloat(v.Float())
+		case isUint(vt):
+			return z.OverflowFloat(float64(v.Uint()))
+		default:
+			return z.OverflowFloat(float64(v.Int()))
+		}
+	case isUint(t):
+		switch {
+		case isFloat(vt):
+			f := v.Float()
+			return f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || z.OverflowUint(uint64(f))
+		case isUint(vt):
+			return z.OverflowUint(v.Uint())
+		default:
+			return v.Int() < 0 || z.OverflowUint(uint64(v.Int()))
+		}
+	case isInt(t):
+		switch {
+		case isFloat(vt):
+			f := v.Float()
+			return f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || z.OverflowInt(int64(f))
+		case isUint(vt):
+			return v.Uint() > math.MaxInt64 || z.OverflowInt(int64(v.Uint()))
+		default:
+			return z.OverflowInt(v.Int())
+		}
+	}
+	return false
+}
+
+// isConstKind returns true if values of kind k may be constants.
+func isConstKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return reflect.Int <= k && k <= reflect.Float64
+}
+
+// FileSet returns the file set of the sources parsed by the interpreter.
+// It is only meaningful for positions obtained from this interpreter.
+func (interp *Interpreter) FileSet() *token.FileSet { return interp.fset }
+
+// Position returns the file, line and column of pos, as reported for
+// instance by the Pos method of compilation errors. The result is only valid
+// for a position in a source evaluated by this interpreter.
+func (interp *Interpreter) Position(pos token.Pos) token.Position {
+	return interp.fset.Position(pos)
+}
+
+// Symbols returns the exported top level symbols of the interpreted package
+// path, or of the main package if path is empty, in the same form as Exports:
+// functions as values, variables as pointers, constants as values and types
+// as nil pointers. Imported symbols are excluded. The map is empty if path is
+// not an interpreted package.
+func (interp *Interpreter) Symbols(path string) map[string]reflect.Value {
+	if path == "" {
+		path = mainID
+	}
+	res := map[string]reflect.Value{}
+
+	interp.mutex.RLock()
+	sc, ok := interp.scopes[path]
+	if !ok {
+		interp.mutex.RUnlock()
+		return res
+	}
+	syms := map[string]*symbol{}
+	for name, sym := range sc.sym {
+		if sym.typ != nil && sym.typ.cat == genericT {
+			continue // A generic has no value until instantiated.
+		}
+		if canExport(name) && !interp.isImportedSymbol(path, name, sym) {
+			syms[name] = sym
+		}
+	}
+	interp.mutex.RUnlock()
+
+	interp.frame.mutex.RLock()
+	defer interp.frame.mutex.RUnlock()
+	for name, sym := range syms {
+		switch sym.kind {
+		case funcSym:
+			if sym.node != nil && sym.node.kind == funcDecl {
+				res[name] = genFunctionWrapper(sym.node)(interp.frame)
+			}
+		case varSym:
+			if sym.index >= 0 && sym.index < len(interp.frame.data) {
+				res[name] = interp.frame.data[sym.index].Addr()
+			}
+		case constSym:
+			n := &node{rval: sym.rval, typ: sym.typ}
+			convertConstantValue(n)
+			res[name] = n.rval
+		case typeSym:
+			res[name] = reflect.Zero(reflect.PtrTo(sym.typ.TypeOf()))
+		}
+	}
+	return res
+}
+
+// isImportedSymbol returns true if sym was imported in package path scope
+// under name by a dot import of another source package.
+func (interp *Interpreter) isImportedSymbol(path, name string, sym *symbol) bool {
+	for p, syms := range interp.srcPkg {
+		if p != path && syms[name] == sym {
+			return true
+		}
+	}
+	return false
+}
+
+// globalSymbol returns the package level symbol name, possibly qualified by
+// a package name or import path, or nil if not found.
+func (interp *Interpreter) globalSymbol(name string) *symbol {
+	pkg, ident := mainID, name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		pkg, ident = name[:i], name[i+1:]
+	}
+
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+	sc, ok := interp.scopes[pkg]
+	if !ok {
+		for path, pkgName := range interp.pkgNames {
+			if pkgName == pkg {
+				sc = interp.scopes[path]
+				break
+			}
+		}
+	}
+	if sc == nil {
+		return nil
+	}
+	return sc.sym[ident]
+}
+
+// checkSignature returns an error if the function types t and want have
+// different parameters or results.
+func checkSignature(t, want reflect.Type) error {
+	switch {
+	case t.NumIn() != want.NumIn():
+		return fmt.Errorf("got %d parameters, want %d", t.NumIn(), want.NumIn())
+	case t.NumOut() != want.NumOut():
+		return fmt.Errorf("got %d results, want %d", t.NumOut(), want.NumOut())
+	case t.IsVariadic() != want.IsVariadic():
+		return errors.New("variadic mismatch")
+	}
+	for i := 0; i < t.NumIn(); i++ {
+		if t.In(i) != want.In(i) {
+			return fmt.Errorf("parameter %d has type %v, want %v", i, t.In(i), want.In(i))
+		}
+	}
+	for i := 0; i < t.NumOut(); i++ {
+		if t.Out(i) != want.Out(i) {
+			return fmt.Errorf("result %d has type %v, want %v", i, t.Out(i), want.Out(i))
+		}
+	}
+	return nil
+}
+
 // stop sends a semaphore to all running frames and closes the chan
-// operation short circuit channel. stop may only be called once per
-// invocation of EvalWithContext.
-func (interp *Interpreter) stop() {
+// operation short circuit channel done of the cancelled evaluation. Frames
+// created afterwards use a new channel, or none, and are not affected.
+func (interp *Interpreter) stop(done chan struct{}) {
 	atomic.AddUint64(&interp.id, 1)
-	close(interp.done)
+	interp.mutex.Lock()
+	defer interp.mutex.Unlock()
+	if interp.done == done {
+		interp.done = nil
+	}
+	close(done)
 }
 
 func (interp *Interpreter) runid() uint64 { return atomic.LoadUint64(&interp.id) }
 
 // getWrapper returns the wrapper type of the corresponding interface, or nil if not found.
 func (interp *Interpreter) getWrapper(t reflect.Type) reflect.Type {
-	if p, ok := interp.binPkg[t.PkgPath()]; ok {
-		return p["_"+t.Name()].Type().Elem()
+	name := "_" + t.Name()
+	if w, ok := interp.binPkg[t.PkgPath()][name]; ok {
+		return w.Type().Elem()
+	}
+	// The interface may be exported under another package path, see Extract.
+	for _, p := range interp.binPkg {
+		if v, ok := p[t.Name()]; ok && v.Kind() == reflect.Ptr && v.Type().Elem() == t {
+			if w, ok := p[name]; ok {
+				return w.Type().Elem()
+			}
+		}
 	}
 	return nil
 }
 
+// RestrictPackages limits the binary packages which can be imported or used
+// by the next evaluations to the allowed import paths. This includes the
+// packages imported before the restriction, but not the code already compiled.
+// Symbols of other packages remain loaded and are usable again once the
+// restriction is lifted. An empty allowed list removes the restriction.
+func (interp *Interpreter) RestrictPackages(allowed []string) {
+	interp.mutex.Lock()
+	defer interp.mutex.Unlock()
+	interp.generation++
+	if len(allowed) == 0 {
+		interp.allowed = nil
+		return
+	}
+	interp.allowed = make(map[string]bool, len(allowed))
+	for _, path := range allowed {
+		interp.allowed[path] = true
+	}
+}
+
+// isAllowed returns true if the binary package path can be imported.
+func (interp *Interpreter) isAllowed(path string) bool {
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+	return interp.allowed == nil || interp.allowed[path]
+}
+
 // Use loads binary runtime symbols in the interpreter context so
 // they can be used in interpreted code.
 func (interp *Interpreter) Use(values Exports) {
+	interp.mutex.Lock()
+	interp.generation++
+	interp.mutex.Unlock()
+
 	for k, v := range values {
 		if k == hooksPath {
 			interp.hooks.Parse(v)
 			continue
 		}
 
-		if interp.binPkg[k] == nil {
-			interp.binPkg[k] = make(map[string]reflect.Value)
+		// Symbol maps may be shared with clones, do not modify them in place.
+		syms := make(map[string]reflect.Value, len(interp.binPkg[k])+len(v))
+		for s, sym := range interp.binPkg[k] {
+			syms[s] = sym
 		}
-
 		for s, sym := range v {
-			interp.binPkg[k][s] = sym
+			syms[s] = sym
+			delete(interp.stdioSyms[k], s)
 		}
+		interp.binPkg[k] = syms
 	}
 
 	// Checks if input values correspond to stdlib packages by looking for one
@@ -576,19 +1408,74 @@ func (interp *Interpreter) Use(values Exports) {
 	}
 }
 
+// Unuse removes binary runtime symbols previously loaded by Use from the
+// interpreter context. If no names are given, the whole package is removed.
+// Removing the "fmt" package also restores the symbols redefined to use
+// the interpreter stdio. Already compiled code is not affected.
+func (interp *Interpreter) Unuse(pkgPath string, names ...string) {
+	interp.mutex.Lock()
+	interp.generation++
+	interp.mutex.Unlock()
+
+	p, ok := interp.binPkg[pkgPath]
+	if !ok {
+		return
+	}
+
+	if len(names) > 0 {
+		syms := make(map[string]reflect.Value, len(p))
+		for s, sym := range p {
+			syms[s] = sym
+		}
+		for _, s := range names {
+			delete(syms, s)
+			delete(interp.stdioSyms[pkgPath], s)
+		}
+		interp.binPkg[pkgPath] = syms
+		return
+	}
+
+	delete(interp.binPkg, pkgPath)
+	delete(interp.stdioSyms, pkgPath)
+	for name, sym := range interp.universe.sym {
+		if sym.kind == pkgSym && sym.typ.cat == binPkgT && sym.typ.path == pkgPath {
+			delete(interp.universe.sym, name)
+		}
+	}
+
+	if pkgPath == "fmt" {
+		for path, orig := range interp.stdioSyms {
+			syms := make(map[string]reflect.Value, len(interp.binPkg[path]))
+			for s, sym := range interp.binPkg[path] {
+				syms[s] = sym
+			}
+			for s, sym := range orig {
+				if sym.IsValid() {
+					syms[s] = sym
+				} else {
+					delete(syms, s)
+				}
+			}
+			interp.binPkg[path] = syms
+		}
+		interp.stdioSyms = nil
+	}
+}
+
 // fixStdio redefines interpreter stdlib symbols to use the standard input,
 // output and errror assigned to the interpreter. The changes are limited to
 // the interpreter only. Global values os.Stdin, os.Stdout and os.Stderr are
 // not changed. Note that it is possible to escape the virtualized stdio by
 // read/write directly to file descriptors 0, 1, 2.
 func fixStdio(interp *Interpreter) {
-	p := interp.binPkg["fmt"]
-	if p == nil {
+	if interp.binPkg["fmt"] == nil {
 		return
 	}
 
-	stdin, stdout, stderr := interp.stdin, interp.stdout, interp.stderr
+	var stdin io.Reader = &cancelReader{interp: interp, r: interp.stdin}
+	stdout, stderr := interp.stdout, interp.stderr
 
+	p := map[string]reflect.Value{}
 	p["Print"] = reflect.ValueOf(func(a ...interface{}) (n int, err error) { return fmt.Fprint(stdout, a...) })
 	p["Printf"] = reflect.ValueOf(func(f string, a ...interface{}) (n int, err error) { return fmt.Fprintf(stdout, f, a...) })
 	p["Println"] = reflect.ValueOf(func(a ...interface{}) (n int, err error) { return fmt.Fprintln(stdout, a...) })
@@ -596,14 +1483,16 @@ func fixStdio(interp *Interpreter) {
 	p["Scan"] = reflect.ValueOf(func(a ...interface{}) (n int, err error) { return fmt.Fscan(stdin, a...) })
 	p["Scanf"] = reflect.ValueOf(func(f string, a ...interface{}) (n int, err error) { return fmt.Fscanf(stdin, f, a...) })
 	p["Scanln"] = reflect.ValueOf(func(a ...interface{}) (n int, err error) { return fmt.Fscanln(stdin, a...) })
+	interp.setStdio("fmt", p)
 
-	if p = interp.binPkg["flag"]; p != nil {
+	if interp.binPkg["flag"] != nil {
 		c := flag.NewFlagSet(os.Args[0], flag.PanicOnError)
 		c.SetOutput(stderr)
-		p["CommandLine"] = reflect.ValueOf(&c).Elem()
+		interp.setStdio("flag", map[string]reflect.Value{"CommandLine": reflect.ValueOf(&c).Elem()})
 	}
 
-	if p = interp.binPkg["log"]; p != nil {
+	if interp.binPkg["log"] != nil {
+		p = map[string]reflect.Value{}
 		l := log.New(stderr, "", log.LstdFlags)
 		// Restrict Fatal symbols to panic instead of exit.
 		p["Fatal"] = reflect.ValueOf(l.Panic)
@@ -623,15 +1512,183 @@ func fixStdio(interp *Interpreter) {
 		p["SetOutput"] = reflect.ValueOf(l.SetOutput)
 		p["SetPrefix"] = reflect.ValueOf(l.SetPrefix)
 		p["Writer"] = reflect.ValueOf(l.Writer)
+		interp.setStdio("log", p)
 	}
 
-	if p = interp.binPkg["os"]; p != nil {
+	if interp.binPkg["os"] != nil {
+		p = map[string]reflect.Value{}
 		p["Stdin"] = reflect.ValueOf(&stdin).Elem()
 		p["Stdout"] = reflect.ValueOf(&stdout).Elem()
 		p["Stderr"] = reflect.ValueOf(&stderr).Elem()
+		// Stop the interpreted program instead of the process, unless
+		// the original os.Exit has been explicitly provided.
+		if e := interp.binPkg["os"]["Exit"]; e.IsValid() && e.Pointer() != reflect.ValueOf(os.Exit).Pointer() {
+			p["Exit"] = reflect.ValueOf(func(code int) { panic(ExitError{code}) })
+		}
+		if interp.env != nil {
+			fixEnv(p, interp.env)
+		}
+		if interp.dir != "" {
+			fixDir(p, interp.dir)
+		}
+		interp.setStdio("os", p)
+	}
+}
+
+// setStdio sets the symbols of the binary package path, keeping their
+// original values in interp.stdioSyms so they can be restored by Unuse.
+func (interp *Interpreter) setStdio(path string, syms map[string]reflect.Value) {
+	if interp.stdioSyms == nil {
+		interp.stdioSyms = Exports{}
+	}
+	orig := interp.stdioSyms[path]
+	if orig == nil {
+		orig = map[string]reflect.Value{}
+		interp.stdioSyms[path] = orig
+	}
+	p := interp.binPkg[path]
+	for s, sym := range syms {
+		if _, ok := orig[s]; !ok {
+			orig[s] = p[s]
+		}
+		p[s] = sym
 	}
 }
 
+// cancelReader is a reader which is unblocked by the cancellation of the
+// current EvalWithContext, in which case io.EOF is returned.
+type cancelReader struct {
+	interp *Interpreter
+	r      io.Reader
+
+	mu      sync.Mutex
+	pending chan readResult // in-flight read interrupted by a cancellation, or nil
+}
+
+type readResult struct {
+	buf []byte
+	err error
+}
+
+func (c *cancelReader) Read(p []byte) (int, error) {
+	c.interp.mutex.RLock()
+	done, cancellable := c.interp.done, c.interp.cancelChan
+	c.interp.mutex.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !cancellable && c.pending == nil {
+		return c.r.Read(p)
+	}
+
+	// Perform the blocking read in a goroutine, so it can be abandoned on
+	// cancellation. The data from an abandoned read is kept for the next call.
+	ch := c.pending
+	if ch == nil {
+		ch = make(chan readResult, 1)
+		buf := make([]byte, len(p))
+		go func() {
+			n, err := c.r.Read(buf)
+			ch <- readResult{buf[:n], err}
+		}()
+	}
+
+	select {
+	case r := <-ch:
+		c.pending = nil
+		n := copy(p, r.buf)
+		if n < len(r.buf) {
+			// Keep the remaining data for the next call.
+			c.pending = make(chan readResult, 1)
+			c.pending <- readResult{r.buf[n:], r.err}
+			return n, nil
+		}
+		return n, r.err
+	case <-done:
+		c.pending = ch
+		return 0, io.EOF
+	}
+}
+
+// fixDir redefines os package file symbols to resolve relative paths from
+// dir instead of the process working directory.
+func fixDir(p map[string]reflect.Value, dir string) {
+	abs := func(name string) string {
+		if name == "" || filepath.IsAbs(name) {
+			return name
+		}
+		return filepath.Join(dir, name)
+	}
+
+	p["Getwd"] = reflect.ValueOf(func() (string, error) { return dir, nil })
+	p["Open"] = reflect.ValueOf(func(name string) (*os.File, error) { return os.Open(abs(name)) })
+	p["Create"] = reflect.ValueOf(func(name string) (*os.File, error) { return os.Create(abs(name)) })
+	p["OpenFile"] = reflect.ValueOf(func(name string, flag int, perm os.FileMode) (*os.File, error) {
+		return os.OpenFile(abs(name), flag, perm)
+	})
+	p["Stat"] = reflect.ValueOf(func(name string) (os.FileInfo, error) { return os.Stat(abs(name)) })
+	p["Lstat"] = reflect.ValueOf(func(name string) (os.FileInfo, error) { return os.Lstat(abs(name)) })
+	p["Mkdir"] = reflect.ValueOf(func(name string, perm os.FileMode) error { return os.Mkdir(abs(name), perm) })
+	p["MkdirAll"] = reflect.ValueOf(func(name string, perm os.FileMode) error { return os.MkdirAll(abs(name), perm) })
+	p["Remove"] = reflect.ValueOf(func(name string) error { return os.Remove(abs(name)) })
+	p["RemoveAll"] = reflect.ValueOf(func(name string) error { return os.RemoveAll(abs(name)) })
+	p["Rename"] = reflect.ValueOf(func(from, to string) error { return os.Rename(abs(from), abs(to)) })
+}
+
+// fixEnv redefines os package environment symbols to operate on the
+// interpreter sandboxed environment instead of the process one.
+func fixEnv(p map[string]reflect.Value, env map[string]string) {
+	var mu sync.RWMutex
+
+	lookupEnv := func(key string) (string, bool) {
+		mu.RLock()
+		defer mu.RUnlock()
+		v, ok := env[key]
+		return v, ok
+	}
+	getenv := func(key string) string {
+		v, _ := lookupEnv(key)
+		return v
+	}
+
+	p["Getenv"] = reflect.ValueOf(getenv)
+	p["LookupEnv"] = reflect.ValueOf(lookupEnv)
+	p["ExpandEnv"] = reflect.ValueOf(func(s string) string { return os.Expand(s, getenv) })
+	p["Environ"] = reflect.ValueOf(func() []string {
+		mu.RLock()
+		defer mu.RUnlock()
+		r := make([]string, 0, len(env))
+		for k, v := range env {
+			r = append(r, k+"="+v)
+		}
+		sort.Strings(r)
+		return r
+	})
+	p["Setenv"] = reflect.ValueOf(func(key, value string) error {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return &os.SyscallError{Syscall: "setenv", Err: syscall.EINVAL}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		env[key] = value
+		return nil
+	})
+	p["Unsetenv"] = reflect.ValueOf(func(key string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(env, key)
+		return nil
+	})
+	p["Clearenv"] = reflect.ValueOf(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for k := range env {
+			delete(env, k)
+		}
+	})
+}
+
 // ignoreScannerError returns true if the error from Go scanner can be safely ignored
 // to let the caller grab one more line before retrying to parse its input.
 func ignoreScannerError(e *scanner.Error, s string) bool {
@@ -711,24 +1768,49 @@ func (interp *Interpreter) REPL() (reflect.Value, error) {
 			cancel()
 			return v, err
 		case line = <-lines:
+			if src == "" && strings.HasPrefix(strings.TrimSpace(line), replCmdPrefix) {
+				// REPL commands are not Go code, do not evaluate them.
+				if e := interp.replCommand(ctx, strings.TrimSpace(line), out); e != nil {
+					fmt.Fprintln(errs, e)
+				}
+				prompt(reflect.Value{})
+				continue
+			}
 			src += line + "\n"
 		}
 
 		v, err = interp.EvalWithContext(ctx, src)
-		if err != nil {
+		if err == nil {
+			interp.mutex.Lock()
+			interp.history = append(interp.history, src)
+			interp.mutex.Unlock()
+		} else {
 			switch e := err.(type) {
 			case scanner.ErrorList:
 				if len(e) > 0 && ignoreScannerError(e[0], line) {
 					continue
 				}
 				fmt.Fprintln(errs, strings.TrimPrefix(e[0].Error(), DefaultSourceName+":"))
+			case ErrorList:
+				for _, e := range e {
+					fmt.Fprintln(errs, e)
+				}
 			case Panic:
 				fmt.Fprintln(errs, e.Value)
 				fmt.Fprintln(errs, string(e.Stack))
+			case ExitError:
+				// The REPL terminates, see below.
 			default:
 				fmt.Fprintln(errs, err)
 			}
 		}
+		if interp.onResult != nil {
+			interp.onResult(src, v, err)
+		}
+		if _, ok := err.(ExitError); ok {
+			cancel()
+			return v, err
+		}
 		if errors.Is(err, context.Canceled) {
 			ctx, cancel = context.WithCancel(context.Background())
 		}
@@ -737,6 +1819,112 @@ func (interp *Interpreter) REPL() (reflect.Value, error) {
 	}
 }
 
+// replCmdPrefix starts the REPL commands, as no Go statement can.
+const replCmdPrefix = ":"
+
+// replCommand executes the REPL command cmd and prints its result to out.
+// The commands are:
+//
+//	:vars         lists the symbols declared in the main package, with their types
+//	:imports      lists the packages imported in the main package
+//	:save <file>  writes the sources successfully evaluated in REPL to file
+//	:load <file>  evaluates the sources of file, as if entered in REPL
+func (interp *Interpreter) replCommand(ctx context.Context, cmd string, out io.Writer) error {
+	if args := strings.Fields(cmd); args[0] == ":save" || args[0] == ":load" {
+		if len(args) != 2 {
+			return fmt.Errorf("usage: %s <file>", args[0])
+		}
+		if args[0] == ":save" {
+			interp.mutex.RLock()
+			src := strings.Join(interp.history, "")
+			interp.mutex.RUnlock()
+			return ioutil.WriteFile(args[1], []byte(src), 0644)
+		}
+		return interp.replLoad(ctx, args[1], out)
+	}
+
+	interp.mutex.RLock()
+	var syms map[string]*symbol
+	if sc := interp.scopes[mainID]; sc != nil {
+		syms = sc.sym
+	}
+	var res []string
+	switch cmd {
+	case ":vars":
+		for name, sym := range syms {
+			if name == "_" {
+				continue
+			}
+			switch sym.kind {
+			case constSym:
+				res = append(res, "const "+name+" "+sym.typ.id())
+			case funcSym:
+				res = append(res, "func "+name+" "+sym.typ.id())
+			case typeSym:
+				t := *sym.typ
+				t.name = ""
+				res = append(res, "type "+name+" "+t.id())
+			case varSym:
+				res = append(res, "var "+name+" "+sym.typ.id())
+			}
+		}
+	case ":imports":
+		seen := map[string]bool{}
+		for name, sym := range syms {
+			if sym.kind != pkgSym || seen[sym.typ.path] {
+				continue
+			}
+			seen[sym.typ.path] = true
+			// Imported package names are suffixed by the source file name.
+			res = append(res, filepath.Dir(name)+" "+strconv.Quote(sym.typ.path))
+		}
+	default:
+		interp.mutex.RUnlock()
+		return fmt.Errorf("unknown command %s, want :vars, :imports, :save or :load", cmd)
+	}
+	interp.mutex.RUnlock()
+
+	sort.Strings(res)
+	for _, r := range res {
+		fmt.Fprintln(out, r)
+	}
+	return nil
+}
+
+// replLoad evaluates the content of file incrementally, as REPL does,
+// until the first error. The REPL commands of file print their result to out.
+func (interp *Interpreter) replLoad(ctx context.Context, file string, out io.Writer) error {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	src := ""
+	for i, line := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+		if src == "" && strings.HasPrefix(strings.TrimSpace(line), replCmdPrefix) {
+			if err := interp.replCommand(ctx, strings.TrimSpace(line), out); err != nil {
+				return fmt.Errorf("%s:%d: %v", file, i+1, err)
+			}
+			continue
+		}
+		src += line + "\n"
+		_, err := interp.EvalWithContext(ctx, src)
+		if e, ok := err.(scanner.ErrorList); ok && len(e) > 0 && ignoreScannerError(e[0], line) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %v", file, i+1, err)
+		}
+		interp.mutex.Lock()
+		interp.history = append(interp.history, src)
+		interp.mutex.Unlock()
+		src = ""
+	}
+	if src != "" {
+		return fmt.Errorf("%s: unexpected end of file", file)
+	}
+	return nil
+}
+
 // getPrompt returns a function which prints a prompt only if input is a terminal.
 func getPrompt(in io.Reader, out io.Writer) func(reflect.Value) {
 	s, ok := in.(interface{ Stat() (os.FileInfo, error) })
@@ -33,16 +33,28 @@ func TestInterpConsistencyBuild(t *testing.T) {
 
 	for _, file := range files {
 		if filepath.Ext(file.Name()) != ".go" ||
+			file.Name() == "append2.go" || // expect error
 			file.Name() == "assign11.go" || // expect error
 			file.Name() == "assign12.go" || // expect error
 			file.Name() == "assign15.go" || // expect error
 			file.Name() == "bad0.go" || // expect error
+			file.Name() == "break3.go" || // expect error
+			file.Name() == "break4.go" || // expect error
+			file.Name() == "closure9.go" || // per-iteration loop variables depend on go.mod go version
+			file.Name() == "closure10.go" || // per-iteration loop variables depend on go.mod go version
 			file.Name() == "const9.go" || // expect error
+			file.Name() == "embed0.go" || // go:embed requires go1.16 to be compiled
 			file.Name() == "export1.go" || // non-main package
 			file.Name() == "export0.go" || // non-main package
 			file.Name() == "for7.go" || // expect error
 			file.Name() == "fun21.go" || // expect error
 			file.Name() == "fun22.go" || // expect error
+			file.Name() == "generic2.go" || // expect error
+			file.Name() == "generic5.go" || // expect error
+			file.Name() == "generic7.go" || // expect error
+			file.Name() == "generic9.go" || // expect error
+			file.Name() == "goto2.go" || // expect error
+			file.Name() == "goto4.go" || // expect error
 			file.Name() == "if2.go" || // expect error
 			file.Name() == "import6.go" || // expect error
 			file.Name() == "init1.go" || // expect error
@@ -52,10 +64,14 @@ func TestInterpConsistencyBuild(t *testing.T) {
 			file.Name() == "op9.go" || // expect error
 			file.Name() == "bltn0.go" || // expect error
 			file.Name() == "method16.go" || // private struct field
+			file.Name() == "method37.go" || // expect error
 			file.Name() == "switch8.go" || // expect error
 			file.Name() == "switch9.go" || // expect error
 			file.Name() == "switch13.go" || // expect error
 			file.Name() == "switch19.go" || // expect error
+			file.Name() == "switch41.go" || // expect error
+			file.Name() == "switch43.go" || // expect error
+			file.Name() == "interface52.go" || // expect error
 			file.Name() == "time0.go" || // display time (similar to random number)
 			file.Name() == "factor.go" || // bench
 			file.Name() == "fib.go" || // bench
@@ -94,6 +110,7 @@ func TestInterpConsistencyBuild(t *testing.T) {
 			file.Name() == "server1.go" || // syntax parsing
 			file.Name() == "server0.go" || // syntax parsing
 			file.Name() == "server.go" || // syntax parsing
+			file.Name() == "recover5.go" || // panic(nil) result depends on go.mod go version
 			file.Name() == "range9.go" { // expect error
 			continue
 		}
@@ -176,11 +193,31 @@ func TestInterpErrorConsistency(t *testing.T) {
 			expectedInterp: "1:1: expected 'package', found println",
 			expectedExec:   "1:1: expected 'package', found println",
 		},
+		{
+			fileName:       "break3.go",
+			expectedInterp: "6:9: invalid break label L",
+			expectedExec:   "6:9: invalid break label L",
+		},
+		{
+			fileName:       "break4.go",
+			expectedInterp: "8:13: invalid continue label L",
+			expectedExec:   "8:13: invalid continue label L",
+		},
 		{
 			fileName:       "const9.go",
 			expectedInterp: "5:2: constant definition loop",
 			expectedExec:   "5:2: constant definition loop",
 		},
+		{
+			fileName:       "goto2.go",
+			expectedInterp: "8:8: goto done jumps over variable declaration at line 10",
+			expectedExec:   "8:8: goto done jumps over",
+		},
+		{
+			fileName:       "goto4.go",
+			expectedInterp: "5:8: goto L jumps into block",
+			expectedExec:   "5:8: goto L jumps into block",
+		},
 		{
 			fileName:       "if2.go",
 			expectedInterp: "7:5: non-bool used as if condition",
@@ -201,6 +238,26 @@ func TestInterpErrorConsistency(t *testing.T) {
 			expectedInterp: "6:2: not enough arguments in call to time.Date",
 			expectedExec:   "6:11: not enough arguments in call to time.Date",
 		},
+		{
+			fileName:       "generic2.go",
+			expectedInterp: "10:14: in call to Max, cannot infer T",
+			expectedExec:   "10:17: in call to Max, cannot infer T",
+		},
+		{
+			fileName:       "generic5.go",
+			expectedInterp: "10:14: float64 does not satisfy interface{~int | ~string} (float64 missing in ~int | ~string)",
+			expectedExec:   "10:19: float64 does not satisfy interface{~int | ~string} (float64 missing in ~int | ~string)",
+		},
+		{
+			fileName:       "generic7.go",
+			expectedInterp: "8:14: func() does not satisfy comparable",
+			expectedExec:   "8:18: func() does not satisfy comparable",
+		},
+		{
+			fileName:       "generic9.go",
+			expectedInterp: "4:9: invalid operation: operator + not defined on T",
+			expectedExec:   "4:9: invalid operation: operator + not defined on x (variable of type T constrained by any)",
+		},
 		{
 			fileName:       "op1.go",
 			expectedInterp: "5:2: invalid operation: mismatched types int and float64",
@@ -235,6 +292,20 @@ func TestInterpErrorConsistency(t *testing.T) {
 			expectedInterp: "37:2: duplicate case Bir in type switch",
 			expectedExec:   "37:2: duplicate case Bir in type switch",
 		},
+		{
+			fileName:       "switch41.go",
+			expectedInterp: "14:7: duplicate case 2 in switch",
+			expectedExec:   "14:7: duplicate case 1 + 1",
+		},
+		{
+			fileName:       "switch43.go",
+			expectedInterp: "7:11: invalid operation: operator + not defined on interface{}",
+			expectedExec:   "7:11: invalid operation: v + 1 (mismatched types interface{} and untyped int)",
+		},
+		{
+			fileName:       "interface52.go",
+			expectedInterp: "9:2: duplicate method F",
+		},
 	}
 
 	for _, test := range testCases {
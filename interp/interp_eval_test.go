@@ -4,14 +4,19 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"go/build"
+	"go/token"
 	"io"
 	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -89,6 +94,7 @@ func TestEvalAssign(t *testing.T) {
 		{src: "f := int64(3.2)", err: "1:39: cannot convert expression of type float64 to type int64"},
 		{src: "g := 1; g <<= 8", res: "256"},
 		{src: "h := 1; h >>= 8", res: "0"},
+		{src: `m := map[string]int{}; m["a"]++; m["a"] += 2; m`, res: "map[a:3]"},
 	})
 }
 
@@ -145,6 +151,28 @@ func TestEvalDecl(t *testing.T) {
 		{pre: func() { eval(t, i, "func f() int {return 4}") }, src: "f()", res: "4"},
 		{pre: func() { eval(t, i, `package foo; var I = 2`) }, src: "foo.I", res: "2"},
 		{pre: func() { eval(t, i, `package foo; func F() int {return 5}`) }, src: "foo.F()", res: "5"},
+		{pre: func() { eval(t, i, "const (_ = iota; KB = 1 << (10 * iota); MB; GB; TB); var n int64 = TB") }, src: "n", res: "1099511627776"},
+		{src: "var o int32 = TB", err: "1:28: 1099511627776 overflows int32"},
+	})
+}
+
+func TestEvalConstOverflow(t *testing.T) {
+	i := interp.New(interp.Options{})
+	runTests(t, i, []testCase{
+		{src: "const a int8 = 1000", err: "1:29: 1000 overflows int8"},
+		{src: "var b byte = 300", err: "1:27: 300 overflows uint8"},
+		{src: "var c int8 = -129", err: "1:27: -129 overflows int8"},
+		{pre: func() { eval(t, i, "var d int8 = -128") }, src: "d", res: "-128"},
+		{src: "var e int8 = 200", err: "1:27: 200 overflows int8"},
+		{src: "var f int8 = 127 + 1", err: "1:27: 128 overflows int8"},
+		{src: "var g uint8 = 3 - 4", err: "1:28: -1 overflows uint8"},
+		{src: "var h rune = 0x7fffffff + 1", err: "1:27: 2147483648 overflows int32"},
+		{src: "var k int = 1.5", err: "1:26: 3/2 truncated to int"},
+		{pre: func() { eval(t, i, "var l int = 2.0") }, src: "l", res: "2"},
+		{src: "m := byte(256)", err: "1:38: constant 256 overflows uint8"},
+		{src: "n := uint(-1)", err: "1:38: constant -1 overflows uint"},
+		{src: "o := float32(1e40)", err: "1:41: constant 1e+40 overflows float32"},
+		{src: "p := int64(-1 << 63); p", res: "-9223372036854775808"},
 	})
 }
 
@@ -159,6 +187,583 @@ func TestEvalFunc(t *testing.T) {
 	})
 }
 
+func TestEvalFuncValue(t *testing.T) {
+	i := interp.New(interp.Options{})
+	eval(t, i, `package foo; func Greet(s string) string { return "hello " + s }`)
+	eval(t, i, `
+func Add(a, b int) int { return a + b }
+func Sum(v ...int) (r int) { for _, x := range v { r += x }; return }
+var n = 3
+`)
+
+	v, err := i.EvalFunc("Add", (func(int, int) int)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := v.Interface().(func(int, int) int)
+	if r := add(add(1, 2), 4); r != 7 {
+		t.Errorf("got %d, want 7", r)
+	}
+
+	type sumFunc func(...int) int
+	v, err = i.EvalFunc("Sum", sumFunc(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := v.Interface().(sumFunc)(1, 2, 3); r != 6 {
+		t.Errorf("got %d, want 6", r)
+	}
+
+	v, err = i.EvalFunc("foo.Greet", (func(string) string)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := v.Interface().(func(string) string)("world"); r != "hello world" {
+		t.Errorf("got %q, want %q", r, "hello world")
+	}
+
+	for _, test := range []struct {
+		name      string
+		prototype interface{}
+		err       string
+	}{
+		{"Add", 1, "prototype of Add is not a function: int"},
+		{"Sub", (func(int, int) int)(nil), "undefined: Sub"},
+		{"n", (func() int)(nil), "n is not a function"},
+		{"Add", (func(int) int)(nil), "cannot use Add as func(int) int: got 2 parameters, want 1"},
+		{"Add", (func(int, int))(nil), "cannot use Add as func(int, int): got 1 results, want 0"},
+		{"Add", (func(int, string) int)(nil), "cannot use Add as func(int, string) int: parameter 1 has type int, want string"},
+		{"Sum", (func([]int) int)(nil), "cannot use Sum as func([]int) int: variadic mismatch"},
+	} {
+		if _, err := i.EvalFunc(test.name, test.prototype); err == nil || err.Error() != test.err {
+			t.Errorf("got error %v, want %s", err, test.err)
+		}
+	}
+}
+
+func TestEvalGetValue(t *testing.T) {
+	i := interp.New(interp.Options{})
+	eval(t, i, `package foo; var Count = 2; const Name = "foo"`)
+	eval(t, i, `
+type T struct{ A int }
+var Result int
+var result = "hidden"
+var Iface interface{} = T{3}
+const Pi = 3.14
+func Compute() { Result = 42 }
+`)
+	eval(t, i, "Compute()")
+
+	for _, test := range []struct {
+		name, res, err string
+	}{
+		{name: "Result", res: "42"},
+		{name: "main.Result", res: "42"},
+		{name: "result", res: "hidden"},
+		{name: "Iface", res: "{3}"},
+		{name: "Pi", res: "3.14"},
+		{name: "foo.Count", res: "2"},
+		{name: "foo.Name", res: "foo"},
+		{name: "Missing", err: "undefined: Missing"},
+		{name: "bar.Count", err: "undefined: bar.Count"},
+		{name: "Compute", err: "Compute is not a variable or a constant"},
+	} {
+		v, err := i.GetValue(test.name)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if res := fmt.Sprintf("%v", v); res != test.res {
+			t.Errorf("%s: got %s, want %s", test.name, res, test.res)
+		}
+	}
+}
+
+func TestEvalSetValue(t *testing.T) {
+	i := interp.New(interp.Options{})
+	i.Use(stdlib.Symbols)
+	eval(t, i, `import "fmt"`)
+	eval(t, i, `
+var Count int64
+var Ratio float32
+var Name string
+var Any interface{}
+var Small int8 = 1
+type I interface{ M() string }
+var Iface I
+const Pi = 3.14
+func Get() string { return fmt.Sprintln(Count, Ratio, Name, Any, Small) }
+`)
+
+	for _, test := range []struct {
+		name string
+		val  interface{}
+		err  string
+	}{
+		{name: "Count", val: 3},
+		{name: "main.Ratio", val: 0.5},
+		{name: "Name", val: "foo"},
+		{name: "Any", val: []int{1, 2}},
+		{name: "Small", val: nil},
+		{name: "Small", val: 300, err: "cannot assign to Small: 300 overflows or truncates to type int8"},
+		{name: "Count", val: 1.5, err: "cannot assign to Count: 1.5 overflows or truncates to type int64"},
+		{name: "Name", val: 65, err: "cannot assign to Name: value of type int is not assignable to type string"},
+		{name: "Count", val: math.NaN(), err: "cannot assign to Count: NaN overflows or truncates to type int64"},
+		{name: "Count", val: uint64(math.MaxUint64), err: "cannot assign to Count: 18446744073709551615 overflows or truncates to type int64"},
+		{name: "Ratio", val: math.MaxFloat64, err: "cannot assign to Ratio: 1.7976931348623157e+308 overflows or truncates to type float32"},
+		{name: "Ratio", val: math.NaN()},
+		{name: "Ratio", val: 0.5},
+		{name: "Iface", val: 1, err: "cannot assign to Iface: type int does not implement main.I"},
+		{name: "Iface", val: testM{}},
+		{name: "Pi", val: 3, err: "cannot assign to Pi"},
+		{name: "Missing", val: 1, err: "undefined: Missing"},
+	} {
+		err := i.SetValue(test.name, test.val)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+
+	res, err := i.Eval("Get()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, want := res.Interface().(string), "3 0.5 foo [1 2] 0\n"; s != want {
+		t.Errorf("got %q, want %q", s, want)
+	}
+	if res, err = i.Eval("Iface.M()"); err != nil {
+		t.Fatal(err)
+	}
+	if s, want := res.Interface().(string), "testM"; s != want {
+		t.Errorf("got %q, want %q", s, want)
+	}
+}
+
+type testM struct{}
+
+func (testM) M() string { return "testM" }
+
+func TestEvalPosition(t *testing.T) {
+	i := interp.New(interp.Options{})
+	_, err := i.Eval("package main\n\nfunc main() {\n\tvar s string = 1\n}")
+	e, ok := err.(interface{ Pos() token.Pos })
+	if !ok {
+		t.Fatalf("got error %v, want an error with a position", err)
+	}
+	if p := i.Position(e.Pos()); p.Filename != interp.DefaultSourceName || p.Line != 4 || p.Column != 17 {
+		t.Errorf("got position %v", p)
+	}
+	if f := i.FileSet().File(e.Pos()); f == nil || f.Name() != interp.DefaultSourceName {
+		t.Errorf("got file %v", f)
+	}
+}
+
+func TestEvalClone(t *testing.T) {
+	i := interp.New(interp.Options{})
+	i.Use(stdlib.Symbols)
+	eval(t, i, `import "strings"`)
+	eval(t, i, `var a = strings.ToUpper("parent")`)
+
+	c := i.Clone()
+	if _, err := c.Eval(`a`); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	eval(t, c, `import "strings"`)
+	eval(t, c, `var a = strings.Repeat("c", 3)`)
+	if v := eval(t, c, `a`).Interface(); v != "ccc" {
+		t.Errorf("got clone a %v", v)
+	}
+	if v := eval(t, i, `a`).Interface(); v != "PARENT" {
+		t.Errorf("got parent a %v", v)
+	}
+
+	c.Use(interp.Exports{"strings": {"Hello": reflect.ValueOf("hello")}})
+	if v := eval(t, c, `strings.Hello`).Interface(); v != "hello" {
+		t.Errorf("got clone strings.Hello %v", v)
+	}
+	if _, err := i.Eval(`strings.Hello`); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestEvalReset(t *testing.T) {
+	i := interp.New(interp.Options{})
+	i.Use(stdlib.Symbols)
+	eval(t, i, `import "strings"`)
+	eval(t, i, `var a = strings.ToUpper("before")`)
+	eval(t, i, `func f() int { return 1 }`)
+
+	i.Reset()
+	for _, src := range []string{"a", "f()", `strings.ToUpper("x")`} {
+		if _, err := i.Eval(src); err == nil {
+			t.Errorf("%s: expected error, got nil", src)
+		}
+	}
+
+	// Binary packages are still available, and names can be declared again.
+	eval(t, i, `import "strings"`)
+	eval(t, i, `var a = strings.Repeat("a", 3)`)
+	eval(t, i, `func f() string { return a }`)
+	if v := eval(t, i, `f()`).Interface(); v != "aaa" {
+		t.Errorf("got %v, want aaa", v)
+	}
+}
+
+func TestEvalErrorList(t *testing.T) {
+	src := `package main
+
+func a() {
+	var s string = 1
+	undefined1()
+}
+
+func b() { _ = undefined2 }
+
+func c() { _ = undefined3 }
+
+func main() {}
+`
+	i := interp.New(interp.Options{})
+	_, err := i.Eval(src)
+	list, ok := err.(interp.ErrorList)
+	if !ok {
+		t.Fatalf("got error %v, want an ErrorList", err)
+	}
+	want := []string{"4:17: cannot convert 1 to string", "8:16: undefined: undefined2", "10:16: undefined: undefined3"}
+	if len(list) != len(want) {
+		t.Fatalf("got %d errors: %v", len(list), list)
+	}
+	for k, e := range list {
+		if e.Error() != want[k] {
+			t.Errorf("got error %d %q, want %q", k, e, want[k])
+		}
+	}
+	if s := err.Error(); s != want[0]+" (and 2 more errors)" {
+		t.Errorf("got %q", s)
+	}
+
+	i = interp.New(interp.Options{MaxErrors: 2})
+	if _, err = i.Eval(src); err == nil || len(err.(interp.ErrorList)) != 2 {
+		t.Errorf("got error %v, want 2 errors", err)
+	}
+
+	i = interp.New(interp.Options{})
+	if _, err = i.Eval(`func f() { _ = undefined }`); err == nil || err.Error() != "1:29: undefined: undefined" {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestEvalMaxCallDepth(t *testing.T) {
+	i := interp.New(interp.Options{MaxCallDepth: 100})
+	eval(t, i, `
+func f(n int) int {
+	if n == 0 {
+		return 0
+	}
+	return 1 + f(n-1)
+}
+
+func g(n int) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = r.(error).Error()
+		}
+	}()
+	f(n)
+	return "ok"
+}
+`)
+	if v := eval(t, i, `g(98)`).Interface(); v != "ok" {
+		t.Errorf("got g(98) %v", v)
+	}
+	if v := eval(t, i, `g(1000)`).Interface(); v != "runtime error: call depth limit exceeded" {
+		t.Errorf("got g(1000) %v", v)
+	}
+	if _, err := i.Eval(`f(1000)`); err == nil || !strings.Contains(err.Error(), "call depth limit exceeded") {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestEvalMaxSteps(t *testing.T) {
+	i := interp.New(interp.Options{MaxSteps: 10000})
+	eval(t, i, `func sum(n int) (s int) { for i := 0; i < n; i++ { s += i }; return }`)
+	eval(t, i, `func loop() { for {} }`)
+
+	if v := eval(t, i, `sum(100)`).Interface(); v != 4950 {
+		t.Errorf("got sum(100) %v", v)
+	}
+	if _, err := i.Eval(`loop()`); err != interp.ErrStepBudget {
+		t.Errorf("got error %v, want %v", err, interp.ErrStepBudget)
+	}
+	if _, err := i.Eval(`sum(100000)`); err != interp.ErrStepBudget {
+		t.Errorf("got error %v, want %v", err, interp.ErrStepBudget)
+	}
+	// The budget is reset at each evaluation.
+	if v := eval(t, i, `sum(100)`).Interface(); v != 4950 {
+		t.Errorf("got sum(100) %v", v)
+	}
+}
+
+func TestEvalDeterministicMaps(t *testing.T) {
+	i := interp.New(interp.Options{DeterministicMaps: true})
+	i.Use(stdlib.Symbols)
+	i.Use(interp.Exports{"bin/bin": {"M": reflect.ValueOf(map[float64]bool{2.5: true, -1: false, 10: true})}})
+	eval(t, i, `
+import (
+	"bin/bin"
+	"fmt"
+)
+
+func keys() (s string) {
+	m := map[string]int{}
+	for _, k := range []string{"z", "b", "y", "a", "x", "c", "w", "d", "v", "e"} {
+		m[k] = len(k)
+	}
+	for k := range m {
+		s += k
+	}
+	return
+}
+
+func values() (s string) {
+	m := map[int]interface{}{3: "c", -2: 4, 1: true, 2: nil}
+	for k, v := range m {
+		if k == 1 {
+			delete(m, 2)
+		}
+		s += fmt.Sprint(v)
+	}
+	return
+}
+
+func bins() (s []float64) {
+	for k := range bin.M {
+		s = append(s, k)
+	}
+	return
+}
+
+func structs() {
+	for range map[struct{}]int{{}: 1} {
+	}
+}
+`)
+	if v := eval(t, i, `keys()`).Interface(); v != "abcdevwxyz" {
+		t.Errorf("got keys() %v", v)
+	}
+	if v := eval(t, i, `values()`).Interface(); v != "4truec" {
+		t.Errorf("got values() %v", v)
+	}
+	if v := fmt.Sprint(eval(t, i, `bins()`).Interface()); v != "[-1 2.5 10]" {
+		t.Errorf("got bins() %v", v)
+	}
+	if _, err := i.Eval(`structs()`); err == nil || !strings.Contains(err.Error(), "cannot range over map with keys of type struct {} in deterministic mode") {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestEvalUnuse(t *testing.T) {
+	var out bytes.Buffer
+	i := interp.New(interp.Options{Stdout: &out})
+	i.Use(stdlib.Symbols)
+	i.Use(interp.Exports{"mock/db": {"Get": reflect.ValueOf(func() string { return "v1" })}})
+	eval(t, i, `import "mock/db"`)
+	if v := eval(t, i, `db.Get()`).Interface(); v != "v1" {
+		t.Errorf("got db.Get() %v", v)
+	}
+
+	i.Unuse("mock/db", "Get")
+	if _, err := i.Eval(`db.Get()`); err == nil {
+		t.Error("expected error, got nil")
+	}
+	i.Use(interp.Exports{"mock/db": {"Get": reflect.ValueOf(func() string { return "v2" })}})
+	if v := eval(t, i, `db.Get()`).Interface(); v != "v2" {
+		t.Errorf("got db.Get() %v", v)
+	}
+
+	eval(t, i, `import "os"`)
+	if v := eval(t, i, `os.Stdout`).Interface(); v == os.Stdout {
+		t.Error("got os.Stdout not redefined")
+	}
+	i.Unuse("fmt")
+	if v := eval(t, i, `os.Stdout`).Interface(); v != os.Stdout {
+		t.Errorf("got os.Stdout %v", v)
+	}
+	if _, err := i.Eval(`import "fmt"`); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestEvalSymbols(t *testing.T) {
+	i := interp.New(interp.Options{})
+	i.Use(stdlib.Symbols)
+	eval(t, i, `package foo; func Hello() string { return "hello" }`)
+	eval(t, i, `
+import "strings"
+type T struct{ A int }
+var Count = 3
+var hidden = 1
+const Name = "yaegi"
+func Upper(s string) string { return strings.ToUpper(s) }
+`)
+
+	syms := i.Symbols("")
+	var names []string
+	for name := range syms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if s := strings.Join(names, " "); s != "Count Name T Upper" {
+		t.Fatalf("got symbols %s", s)
+	}
+	if v := syms["Count"].Elem().Interface(); v != 3 {
+		t.Errorf("got Count %v", v)
+	}
+	if v := syms["Name"].Interface(); v != "yaegi" {
+		t.Errorf("got Name %v", v)
+	}
+	if typ := syms["T"].Type().Elem(); typ.Kind() != reflect.Struct || typ.NumField() != 1 {
+		t.Errorf("got T %v", typ)
+	}
+	if v := syms["Upper"].Call([]reflect.Value{reflect.ValueOf("abc")})[0].Interface(); v != "ABC" {
+		t.Errorf("got Upper(\"abc\") %v", v)
+	}
+
+	if syms := i.Symbols("foo"); len(syms) != 1 || !syms["Hello"].IsValid() {
+		t.Errorf("got foo symbols %v", syms)
+	}
+	if syms := i.Symbols("unknown"); syms == nil || len(syms) != 0 {
+		t.Errorf("got unknown symbols %v", syms)
+	}
+}
+
+func TestEvalMulti(t *testing.T) {
+	i := interp.New(interp.Options{})
+	i.Use(stdlib.Symbols)
+	eval(t, i, `import "strconv"`)
+	eval(t, i, `func pair() (int, string) { return 1, "one" }`)
+
+	for _, test := range []struct {
+		src, res string
+	}{
+		{src: "pair()", res: `[1 one]`},
+		{src: "a, b := pair()", res: `[1 one]`},
+		{src: "c, d := 2, 3", res: `[2 3]`},
+		{src: "a, b = 4, b", res: `[]`},
+		{src: `strconv.Atoi("12")`, res: `[12 <nil>]`},
+		{src: "a + c", res: `[6]`},
+		{src: `println("")`, res: `[]`},
+		{src: "var e, f = pair()", res: `[1 one]`},
+	} {
+		res, err := i.EvalMulti(test.src)
+		if err != nil {
+			t.Errorf("%s: %v", test.src, err)
+			continue
+		}
+		if res == nil {
+			t.Errorf("%s: got nil result", test.src)
+		}
+		var values []interface{}
+		for _, v := range res {
+			values = append(values, v.Interface())
+		}
+		if s := fmt.Sprint(values); s != test.res {
+			t.Errorf("%s: got %s, want %s", test.src, s, test.res)
+		}
+	}
+}
+
+func TestEvalPanicFrames(t *testing.T) {
+	i := interp.New(interp.Options{Stderr: ioutil.Discard})
+	eval(t, i, `
+type T struct{}
+
+func (t *T) fail(s string) {
+	panic(s)
+}
+
+func run() {
+	t := &T{}
+	func() {
+		t.fail("boom")
+	}()
+}
+`)
+
+	for _, test := range []struct {
+		src, frames string
+	}{
+		{src: "run()", frames: "main.(*T).fail _.go:5, main.run.func1 _.go:11, main.run _.go:10, _.go:1"},
+		{src: `panic("top")`, frames: "_.go:1"},
+	} {
+		_, err := i.Eval(test.src)
+		p, ok := err.(interp.Panic)
+		if !ok {
+			t.Errorf("%s: got error %v, want a panic", test.src, err)
+			continue
+		}
+		var frames []string
+		for _, f := range p.InterpFrames {
+			s := fmt.Sprintf("%s:%d", f.File, f.Line)
+			if f.Function != "" {
+				s = f.Function + " " + s
+			}
+			frames = append(frames, s)
+		}
+		if s := strings.Join(frames, ", "); s != test.frames {
+			t.Errorf("%s: got frames %s, want %s", test.src, s, test.frames)
+		}
+	}
+}
+
+func TestEvalExit(t *testing.T) {
+	var stdout bytes.Buffer
+	i := interp.New(interp.Options{Stdout: &stdout})
+	i.Use(stdlib.Symbols)
+	eval(t, i, `
+import (
+	"fmt"
+	"os"
+)
+
+func quit(code int) {
+	defer func() {
+		fmt.Println("recovered:", recover())
+	}()
+	os.Exit(code)
+}
+`)
+
+	_, err := i.Eval("fmt.Println(1); quit(3); fmt.Println(2)")
+	if e, ok := err.(interp.ExitError); !ok || e.Code != 3 {
+		t.Fatalf("got error %v, want an exit error with code 3", err)
+	}
+	if err.Error() != "exit status 3" {
+		t.Errorf("got %q, want %q", err, "exit status 3")
+	}
+	// Nothing runs after exit, not even deferred functions.
+	if s := stdout.String(); s != "1\n" {
+		t.Errorf("got output %q, want %q", s, "1\n")
+	}
+
+	// The interpreter remains usable.
+	if v := eval(t, i, "fmt.Sprint(4)"); v.Interface() != "4" {
+		t.Errorf("got %v, want 4", v)
+	}
+}
+
 func TestEvalImport(t *testing.T) {
 	i := interp.New(interp.Options{})
 	i.Use(stdlib.Symbols)
@@ -167,6 +772,70 @@ func TestEvalImport(t *testing.T) {
 	})
 }
 
+func TestEvalImportResolver(t *testing.T) {
+	var paths []string
+	i := interp.New(interp.Options{ImportResolver: func(path string) (string, error) {
+		paths = append(paths, path)
+		switch path {
+		case "os":
+			return "", errors.New("not allowed")
+		case "example.com/strings":
+			return "strings", nil
+		}
+		return path, nil
+	}})
+	i.Use(stdlib.Symbols)
+
+	eval(t, i, `import "fmt"; import str "example.com/strings"`)
+	if v := eval(t, i, `fmt.Sprint(str.ToUpper("hello"))`); v.Interface() != "HELLO" {
+		t.Errorf("got %v, want HELLO", v)
+	}
+	_, err := i.Eval(`import "os"`)
+	if want := `1:21: import "os" denied: not allowed`; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %s", err, want)
+	}
+	if s := strings.Join(paths, " "); s != "fmt example.com/strings os" {
+		t.Errorf("got resolved paths %s", s)
+	}
+}
+
+func TestEvalRestrictPackages(t *testing.T) {
+	i := interp.New(interp.Options{})
+	i.Use(stdlib.Symbols)
+
+	eval(t, i, `import ("bytes"; "os")`)
+	eval(t, i, `func sep() string { return string(os.PathSeparator) }`)
+
+	i.RestrictPackages([]string{"fmt", "strings"})
+	eval(t, i, `import ("fmt"; "strings")`)
+	if v := eval(t, i, `fmt.Sprint(strings.Repeat("a", 2))`); v.Interface() != "aa" {
+		t.Errorf("got %v, want aa", v)
+	}
+	_, err := i.Eval(`import "os"`)
+	if want := `1:21: import "os" denied: package not allowed`; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %s", err, want)
+	}
+
+	// Packages imported before the restriction are denied too, except in
+	// code already compiled.
+	_, err = i.Eval(`os.Getenv("HOME")`)
+	if want := `1:28: use of package "os" denied: package not allowed`; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %s", err, want)
+	}
+	_, err = i.Eval(`var b bytes.Buffer`)
+	if want := `1:20: use of package "bytes" denied: package not allowed`; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %s", err, want)
+	}
+	if v := eval(t, i, `sep()`); v.Len() != 1 {
+		t.Errorf("got %v, want a path separator", v)
+	}
+
+	i.RestrictPackages(nil)
+	if v := eval(t, i, `os.PathSeparator == '/' || os.PathSeparator == '\\'`); !v.Bool() {
+		t.Errorf("got %v, want true", v)
+	}
+}
+
 func TestEvalStdout(t *testing.T) {
 	var out, err bytes.Buffer
 	i := interp.New(interp.Options{Stdout: &out, Stderr: &err})
@@ -181,6 +850,203 @@ func TestEvalStdout(t *testing.T) {
 	}
 }
 
+func TestEvalEnv(t *testing.T) {
+	if err := os.Setenv("YAEGI_TEST_HOST_ONLY", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("YAEGI_TEST_HOST_ONLY")
+
+	i := interp.New(interp.Options{Env: map[string]string{"FOO": "bar", "HOME": "/sandbox"}})
+	i.Use(stdlib.Symbols)
+	eval(t, i, `import "os"`)
+	runTests(t, i, []testCase{
+		{desc: "getenv", src: `os.Getenv("FOO")`, res: "bar"},
+		{desc: "host only", src: `os.Getenv("YAEGI_TEST_HOST_ONLY")`, res: ""},
+		{desc: "lookupenv", src: `_, ok := os.LookupEnv("YAEGI_TEST_HOST_ONLY"); ok`, res: "false"},
+		{desc: "environ", src: `os.Environ()`, res: "[FOO=bar HOME=/sandbox]"},
+		{desc: "expandenv", src: `os.ExpandEnv("$HOME/x")`, res: "/sandbox/x"},
+		{desc: "setenv", src: `os.Setenv("BAZ", "1"); os.Getenv("BAZ")`, res: "1"},
+	})
+	if _, ok := os.LookupEnv("BAZ"); ok {
+		t.Fatal("sandboxed Setenv changed the process environment")
+	}
+}
+
+func TestEvalDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yaegi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	i := interp.New(interp.Options{Dir: dir})
+	i.Use(stdlib.Symbols)
+	eval(t, i, `import ("io/ioutil"; "os")`)
+	runTests(t, i, []testCase{
+		{desc: "getwd", src: `wd, _ := os.Getwd(); wd`, res: dir},
+		{desc: "create", src: `f, _ := os.Create("data.txt"); f.WriteString("hello"); f.Close()`, res: "<nil>"},
+		{desc: "open", src: `f, _ := os.Open("./data.txt"); b, _ := ioutil.ReadAll(f); f.Close(); string(b)`, res: "hello"},
+		{desc: "stat", src: `fi, _ := os.Stat("data.txt"); fi.Size()`, res: "5"},
+	})
+	if b, err := ioutil.ReadFile(filepath.Join(dir, "data.txt")); err != nil || string(b) != "hello" {
+		t.Errorf("got %q, %v, want hello", b, err)
+	}
+	if _, err := os.Stat("data.txt"); err == nil {
+		t.Error("file created in the process working directory")
+	}
+}
+
+func TestEvalGeneric(t *testing.T) {
+	// Type parameters can only be parsed by go1.18 and later.
+	var hasTypeParams bool
+	for _, tag := range build.Default.ReleaseTags {
+		if tag == "go1.18" {
+			hasTypeParams = true
+		}
+	}
+	if !hasTypeParams {
+		t.Skip("type parameters not supported")
+	}
+
+	i := interp.New(interp.Options{})
+	i.Use(stdlib.Symbols)
+	runTests(t, i, []testCase{
+		{
+			desc: "multiple type parameters",
+			pre: func() {
+				eval(t, i, `
+					import "strconv"
+
+					func Map[T, U any](s []T, f func(T) U) []U {
+						r := make([]U, len(s))
+						for i, v := range s {
+							r[i] = f(v)
+						}
+						return r
+					}
+
+					func itoa(i int) string { return strconv.Itoa(i) }
+				`)
+			},
+			src: "Map([]int{1, 2, 3}, itoa)",
+			res: "[1 2 3]",
+		},
+		{desc: "explicit type arguments", src: "len(Map[int, string]([]int{4, 5}, itoa)[1])", res: "1"},
+		{desc: "partial type arguments", src: `Map[string]([]string{"a"}, func(s string) int { return len(s) })`, res: "[1]"},
+		{desc: "generic function without instantiation", src: "a := Map", err: "1:33: cannot use generic function Map without instantiation"},
+		{desc: "too many type arguments", src: "Map[int, string, bool]", err: "1:28: got 3 type arguments but Map has 2 type parameters"},
+		{desc: "uninferred type parameter", src: "Map[int]", err: "1:28: cannot infer U"},
+		{
+			desc: "variadic type parameter",
+			pre: func() {
+				eval(t, i, `
+					func Max[T int | float64 | string](vals ...T) T {
+						m := vals[0]
+						for _, v := range vals {
+							if v > m {
+								m = v
+							}
+						}
+						return m
+					}
+				`)
+			},
+			src: "Max(3, 1, 2)",
+			res: "3",
+		},
+		{desc: "variadic string type parameter", src: `Max("a", "b")`, res: "b"},
+		{desc: "variadic untyped float type parameter", src: "Max(1, 2.5)", res: "2.5"},
+		{desc: "variadic empty call", src: "Max()", err: "1:28: in call to Max, cannot infer T"},
+		{desc: "variadic unsatisfied constraint", src: "Max(true)", err: "1:28: bool does not satisfy int | float64 | string (bool missing in int | float64 | string)"},
+		{
+			desc: "constraint interface",
+			pre: func() {
+				eval(t, i, `
+					type Number interface {
+						int | float64
+					}
+
+					func Sum[T Number](s []T) T {
+						var r T
+						for _, v := range s {
+							r += v
+						}
+						return r
+					}
+				`)
+			},
+			src: "Sum([]int{1, 2, 3})",
+			res: "6",
+		},
+		{desc: "constraint interface float64", src: "Sum([]float64{1.5, 2})", res: "3.5"},
+		{desc: "constraint interface unsatisfied", src: `Sum([]string{"a"})`, err: "1:28: string does not satisfy Number (string missing in int | float64)"},
+		{desc: "constraint interface as type", src: "var n Number", err: "1:20: cannot use type Number outside a type constraint: interface contains type constraints"},
+		{desc: "operator not in type set", src: "func Mod[T Number](a, b T) T { return a % b }", err: "1:52: invalid operation: operator % not defined on T"},
+		{desc: "operator on any", src: "func Add[T any](a, b T) T { return a + b }", err: "1:49: invalid operation: operator + not defined on T"},
+		{
+			desc: "inline constraint",
+			pre: func() {
+				eval(t, i, `
+					type MyInt int
+
+					func Twice[T interface{ ~int | ~string }](x T) T { return x + x }
+				`)
+			},
+			src: `Twice("ab")`,
+			res: "abab",
+		},
+		{desc: "inline constraint approximation", src: "Twice(MyInt(4))", res: "8"},
+		{
+			desc: "inline core type",
+			pre:  func() { eval(t, i, "func First[S interface{ ~[]E }, E any](s S) E { return s[0] }") },
+			src:  `First([]string{"x"})`,
+			res:  "x",
+		},
+		{desc: "inline constraint unsatisfied", src: "Twice(1.5)", err: "1:28: float64 does not satisfy interface{~int | ~string} (float64 missing in ~int | ~string)"},
+		{
+			desc: "generic map cache",
+			pre: func() {
+				eval(t, i, `
+					type Cache[K comparable, V any] struct {
+						m map[K]V
+					}
+
+					func (c *Cache[K, V]) Get(k K) (V, bool) {
+						v, ok := c.m[k]
+						return v, ok
+					}
+
+					func (c *Cache[K, V]) Set(k K, v V) {
+						if c.m == nil {
+							c.m = map[K]V{}
+						}
+						c.m[k] = v
+					}
+
+					var c Cache[string, int]
+
+					func init() { c.Set("a", 1) }
+				`)
+			},
+			src: `c.Get("a")`,
+			res: "1",
+		},
+		{desc: "generic type without instantiation", src: "var d Cache", err: "1:20: cannot use generic type Cache without instantiation"},
+		{desc: "generic type unsatisfied comparable", src: "var d Cache[func(), int]", err: "1:26: func() does not satisfy comparable"},
+		{desc: "comparable ordered operator", src: "func Less[T comparable](a, b T) bool { return a < b }", err: "1:60: invalid operation: operator < not defined on T"},
+		{
+			desc: "instantiated function value",
+			pre: func() {
+				eval(t, i, `var f func([]int, func(int) string) []string = Map[int, string]`)
+			},
+			src: "f([]int{7}, itoa)",
+			res: "[7]",
+		},
+		{desc: "import slices", src: `import "slices"`, err: `1:21: import "slices" error: generic functions of binary packages are not supported`},
+		{desc: "import maps", src: `import "maps"`, err: `1:21: import "maps" error: generic functions of binary packages are not supported`},
+	})
+}
+
 func TestEvalNil(t *testing.T) {
 	i := interp.New(interp.Options{})
 	i.Use(stdlib.Symbols)
@@ -493,6 +1359,44 @@ func TestEvalMethod(t *testing.T) {
 	})
 }
 
+func TestEvalMethodExpr(t *testing.T) {
+	i := interp.New(interp.Options{})
+	eval(t, i, `
+		type Root struct {
+			Name string
+		}
+
+		type One struct {
+			Root
+		}
+
+		func (r Root) Hello() string { return "Hello " + r.Name }
+		func (r *Root) Rename(s string) { r.Name = s }
+
+		var r = &Root{"R"}
+		var o = &One{Root{"O"}}
+	`)
+	runTests(t, i, []testCase{
+		{src: "Root.Hello(*r)", res: "Hello R"},
+		{src: "(*Root).Hello(r)", res: "Hello R"},
+		{src: "One.Hello(*o)", res: "Hello O"},
+		{src: "(*One).Hello(o)", res: "Hello O"},
+		{src: "(*One).Rename(o, \"P\"); o.Name", res: "P"},
+	})
+
+	// Method expressions are callable from the host, receiver first.
+	r := eval(t, i, "r")
+	hello := eval(t, i, "(*Root).Hello").Interface().(func(*struct{ Name string }) string)
+	if s := hello(r.Interface().(*struct{ Name string })); s != "Hello R" {
+		t.Fatalf("got %q, want %q", s, "Hello R")
+	}
+	rename := eval(t, i, "(*One).Rename")
+	rename.Call([]reflect.Value{eval(t, i, "o"), reflect.ValueOf("Q")})
+	if s := eval(t, i, "o.Name").String(); s != "Q" {
+		t.Fatalf("got %q, want %q", s, "Q")
+	}
+}
+
 func TestEvalChan(t *testing.T) {
 	i := interp.New(interp.Options{})
 	runTests(t, i, []testCase{
@@ -523,67 +1427,440 @@ func TestEvalChan(t *testing.T) {
 			})()`, res: "true",
 		},
 	})
-}
+}
+
+func TestEvalChanBin(t *testing.T) {
+	produce := func(c chan<- int, n int) {
+		go func() {
+			for i := 0; i < n; i++ {
+				c <- i
+			}
+			close(c)
+		}()
+	}
+	i := interp.New(interp.Options{})
+	i.Use(interp.Exports{"p": map[string]reflect.Value{
+		"Produce": reflect.ValueOf(produce),
+		"Gen": reflect.ValueOf(func(n int) <-chan int {
+			c := make(chan int)
+			produce(c, n)
+			return c
+		}),
+		"Forward": reflect.ValueOf(func(in <-chan interface{}, out chan<- interface{}) {
+			go func() {
+				for v := range in {
+					out <- v
+				}
+				close(out)
+			}()
+		}),
+		"Send": reflect.ValueOf(func(c chan<- interface{}) {
+			go func() { c <- 42; close(c) }()
+		}),
+	}})
+	if _, err := i.Eval(`import "p"`); err != nil {
+		t.Fatal(err)
+	}
+	runTests(t, i, []testCase{
+		{
+			src: `(func () int {
+				c := make(chan int)
+				p.Produce(c, 4)
+				s := 0
+				for v := range c {
+					s += v
+				}
+				return s
+			})()`, res: "6",
+		},
+		{
+			src: `(func () int {
+				s := 0
+				for v := range p.Gen(5) {
+					s += v
+				}
+				return s
+			})()`, res: "10",
+		},
+		{
+			src: `(func () bool {
+				type T struct{ A int }
+				in, out := make(chan interface{}), make(chan interface{})
+				p.Forward(in, out)
+				go func() { in <- T{1}; close(in) }()
+				v, ok := <-out
+				t, _ := v.(T)
+				return ok && t.A == 1
+			})()`, res: "true",
+		},
+		{
+			src: `(func () int {
+				c := make(chan interface{})
+				p.Send(c)
+				return (<-c).(int)
+			})()`, res: "42",
+		},
+	})
+}
+
+func TestEvalFunctionCallWithFunctionParam(t *testing.T) {
+	i := interp.New(interp.Options{})
+	eval(t, i, `
+		func Bar(s string, fn func(string)string) string { return fn(s) }
+	`)
+
+	v := eval(t, i, "Bar")
+	bar := v.Interface().(func(string, func(string) string) string)
+
+	got := bar("hello ", func(s string) string {
+		return s + "world!"
+	})
+
+	want := "hello world!"
+	if got != want {
+		t.Errorf("unexpected result of function eval: got %q, want %q", got, want)
+	}
+}
+
+func TestEvalCall(t *testing.T) {
+	i := interp.New(interp.Options{})
+	runTests(t, i, []testCase{
+		{src: ` test := func(a int, b float64) int { return a }
+				a := test(1, 2.3)`, res: "1"},
+		{src: ` test := func(a int, b float64) int { return a }
+				a := test(1)`, err: "2:10: not enough arguments in call to test"},
+		{src: ` test := func(a int, b float64) int { return a }
+				s := "test"
+				a := test(1, s)`, err: "3:18: cannot use type string as type float64"},
+		{src: ` test := func(a ...int) int { return 1 }
+				a := test([]int{1}...)`, res: "1"},
+		{src: ` test := func(a ...int) int { return 1 }
+				a := test()`, res: "1"},
+		{src: ` test := func(a ...int) int { return 1 }
+				blah := func() []int { return []int{1,1} }
+				a := test(blah()...)`, res: "1"},
+		{src: ` test := func(a ...int) int { return 1 }
+				a := test([]string{"1"}...)`, err: "2:15: cannot use []string as type []int"},
+		{src: ` test := func(a ...int) int { return 1 }
+				i := 1
+				a := test(i...)`, err: "3:15: cannot use int as type []int"},
+		{src: ` test := func(a int) int { return a }
+				a := test([]int{1}...)`, err: "2:10: invalid use of ..., corresponding parameter is non-variadic"},
+		{src: ` test := func(a ...int) int { return 1 }
+				blah := func() (int, int) { return 1, 1 }
+				a := test(blah()...)`, err: "3:15: cannot use ... with 2-valued func()(int,int)"},
+		{src: ` test := func(a, b int) int { return a }
+				blah := func() (int, int) { return 1, 1 }
+				a := test(blah())`, res: "1"},
+		{src: ` test := func(a, b int) int { return a }
+				blah := func() int { return 1 }
+				a := test(blah(), blah())`, res: "1"},
+		{src: ` test := func(a, b, c, d int) int { return a }
+				blah := func() (int, int) { return 1, 1 }
+				a := test(blah(), blah())`, err: "3:15: cannot use func()(int,int) as type int"},
+		{src: ` test := func(a, b, c int) int { return a }
+				blah := func() (int, int) { return 1, 1 }
+				a := test(1, blah())`, err: "3:18: cannot use func()(int,int) as type int"},
+		{src: ` test := func(a, b int) int { return a }
+				blah := func() (int, float64) { return 1, 1.1 }
+				a := test(blah())`, err: "3:15: cannot use func()(int,float64) as type (int,int)"},
+		{src: ` test := func(a ...int) int { return len(a) }
+				a := test(nil...)`, res: "0"},
+		{src: ` test := func(a ...interface{}) int { return len(a) }
+				l := []interface{}{1, 2}
+				a := test(l)`, res: "1"},
+		{src: ` test := func(a ...interface{}) int { return len(a) }
+				l := []interface{}{1, 2}
+				a := test(l...)`, res: "2"},
+	})
+}
+
+func TestEvalVariadicFromHost(t *testing.T) {
+	i := interp.New(interp.Options{})
+	eval(t, i, `
+		func count(a ...interface{}) int { return len(a) }
+		func sum(b int, a ...int) int {
+			for _, v := range a {
+				b += v
+			}
+			return b
+		}
+	`)
+
+	count := eval(t, i, "count").Interface().(func(...interface{}) int)
+	if n := count([]interface{}{1, "a", nil}...); n != 3 {
+		t.Errorf("got %d, want 3", n)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("got %d, want 0", n)
+	}
+	sum := eval(t, i, "sum").Interface().(func(int, ...int) int)
+	if n := sum(1, []int{2, 3}...); n != 6 {
+		t.Errorf("got %d, want 6", n)
+	}
+}
+
+func TestEvalHostInterface(t *testing.T) {
+	var out io.Writer = &bytes.Buffer{}
+	i := interp.New(interp.Options{})
+	i.Use(stdlib.Symbols)
+	i.Use(interp.Exports{"host": {"Out": reflect.ValueOf(&out).Elem()}})
+	eval(t, i, `
+		import (
+			"bytes"
+			"fmt"
+			"io"
+			"host"
+		)
+
+		func Write(w io.Writer) string {
+			fmt.Fprint(w, "a")
+			s := ""
+			switch v := w.(type) {
+			case *bytes.Buffer:
+				s += "buffer:" + v.String()
+			default:
+				s += "other"
+			}
+			if b, ok := w.(*bytes.Buffer); ok {
+				b.WriteString("b")
+			}
+			return s + " " + w.(fmt.Stringer).String()
+		}
+
+		func Value(v interface{}) string {
+			s := ""
+			switch w := v.(type) {
+			case io.Writer:
+				w.Write([]byte("c"))
+				s += "writer"
+			default:
+				s += "other"
+			}
+			if _, ok := v.(io.Reader); ok {
+				s += " reader"
+			}
+			if b, ok := v.(*bytes.Buffer); ok {
+				s += " " + b.String()
+			}
+			return s
+		}
+
+		func Host() string {
+			var v interface{} = host.Out
+			switch w := v.(type) {
+			case *bytes.Buffer:
+				w.WriteString("d")
+			}
+			return v.(*bytes.Buffer).String()
+		}
+	`)
+
+	write := eval(t, i, "Write").Interface().(func(io.Writer) string)
+	if s, want := write(&bytes.Buffer{}), "buffer:a ab"; s != want {
+		t.Errorf("got %q, want %q", s, want)
+	}
+	value := eval(t, i, "Value").Interface().(func(interface{}) string)
+	if s, want := value(&bytes.Buffer{}), "writer reader c"; s != want {
+		t.Errorf("got %q, want %q", s, want)
+	}
+	if s, want := value(nil), "other"; s != want {
+		t.Errorf("got %q, want %q", s, want)
+	}
+	if s, want := eval(t, i, "Host()").String(), "d"; s != want {
+		t.Errorf("got %q, want %q", s, want)
+	}
+}
+
+type Shape interface{ Area() float64 }
+
+type Square struct{ Side float64 }
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+// _geo_Shape is a wrapper for the Shape interface, as generated by goexports.
+type _geo_Shape struct{ WArea func() float64 }
+
+func (W _geo_Shape) Area() float64 { return W.WArea() }
+
+func TestExtract(t *testing.T) {
+	unit := 1.0
+	total := func(shapes ...Shape) (a float64) {
+		for _, s := range shapes {
+			a += s.Area() * unit
+		}
+		return a
+	}
+	exports, err := interp.Extract("geo", map[string]interface{}{
+		"Shape":  (*Shape)(nil),
+		"_Shape": (*_geo_Shape)(nil),
+		"Square": (*Square)(nil),
+		"Total":  total,
+		"Unit":   &unit,
+		"Max":    100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
 
-func TestEvalFunctionCallWithFunctionParam(t *testing.T) {
 	i := interp.New(interp.Options{})
+	i.Use(exports)
 	eval(t, i, `
-		func Bar(s string, fn func(string)string) string { return fn(s) }
-	`)
+		import "geo"
 
-	v := eval(t, i, "Bar")
-	bar := v.Interface().(func(string, func(string) string) string)
+		type Rect struct{ W, H float64 }
 
-	got := bar("hello ", func(s string) string {
-		return s + "world!"
+		func (r Rect) Area() float64 { return r.W * r.H }
+
+		var s geo.Shape = geo.Square{2}
+	`)
+	runTests(t, i, []testCase{
+		{src: "s.Area()", res: "4"},
+		{src: "geo.Total(s, Rect{2, 3})", res: "10"},
+		{src: "geo.Unit = 2; geo.Total(Rect{1, 1})", res: "2"},
+		{src: "geo.Max", res: "100"},
 	})
+	if unit != 2 {
+		t.Errorf("got %v, want 2", unit)
+	}
 
-	want := "hello world!"
-	if got != want {
-		t.Errorf("unexpected result of function eval: got %q, want %q", got, want)
+	for _, test := range []struct {
+		symbols map[string]interface{}
+		err     string
+	}{
+		{map[string]interface{}{"X": nil}, "geo.X: nil value"},
+		{map[string]interface{}{"_Shape": (*_geo_Shape)(nil)}, "geo._Shape: no interface Shape for wrapper"},
+		{map[string]interface{}{"Shape": (*Shape)(nil), "_Shape": _geo_Shape{}}, "geo._Shape: wrapper is not a nil pointer to struct"},
+		{map[string]interface{}{"Shape": (*Shape)(nil), "_Shape": (*struct{ WArea func() float64 })(nil)}, "geo._Shape: invalid wrapper for interp_test.Shape"},
+		{map[string]interface{}{"Shape": (*Shape)(nil), "_Shape": (*Square)(nil)}, "geo._Shape: invalid wrapper field Side for method Area"},
+	} {
+		if _, err := interp.Extract("geo", test.symbols); err == nil || err.Error() != test.err {
+			t.Errorf("got error %v, want %q", err, test.err)
+		}
 	}
 }
 
-func TestEvalCall(t *testing.T) {
-	i := interp.New(interp.Options{})
-	runTests(t, i, []testCase{
-		{src: ` test := func(a int, b float64) int { return a }
-				a := test(1, 2.3)`, res: "1"},
-		{src: ` test := func(a int, b float64) int { return a }
-				a := test(1)`, err: "2:10: not enough arguments in call to test"},
-		{src: ` test := func(a int, b float64) int { return a }
-				s := "test"
-				a := test(1, s)`, err: "3:18: cannot use type string as type float64"},
-		{src: ` test := func(a ...int) int { return 1 }
-				a := test([]int{1}...)`, res: "1"},
-		{src: ` test := func(a ...int) int { return 1 }
-				a := test()`, res: "1"},
-		{src: ` test := func(a ...int) int { return 1 }
-				blah := func() []int { return []int{1,1} }
-				a := test(blah()...)`, res: "1"},
-		{src: ` test := func(a ...int) int { return 1 }
-				a := test([]string{"1"}...)`, err: "2:15: cannot use []string as type []int"},
-		{src: ` test := func(a ...int) int { return 1 }
-				i := 1
-				a := test(i...)`, err: "3:15: cannot use int as type []int"},
-		{src: ` test := func(a int) int { return a }
-				a := test([]int{1}...)`, err: "2:10: invalid use of ..., corresponding parameter is non-variadic"},
-		{src: ` test := func(a ...int) int { return 1 }
-				blah := func() (int, int) { return 1, 1 }
-				a := test(blah()...)`, err: "3:15: cannot use ... with 2-valued func()(int,int)"},
-		{src: ` test := func(a, b int) int { return a }
-				blah := func() (int, int) { return 1, 1 }
-				a := test(blah())`, res: "1"},
-		{src: ` test := func(a, b int) int { return a }
-				blah := func() int { return 1 }
-				a := test(blah(), blah())`, res: "1"},
-		{src: ` test := func(a, b, c, d int) int { return a }
-				blah := func() (int, int) { return 1, 1 }
-				a := test(blah(), blah())`, err: "3:15: cannot use func()(int,int) as type int"},
-		{src: ` test := func(a, b int) int { return a }
-				blah := func() (int, float64) { return 1, 1.1 }
-				a := test(blah())`, err: "3:15: cannot use func()(int,float64) as type (int,int)"},
-	})
+func TestImports(t *testing.T) {
+	i := interp.New(interp.Options{GoPath: filepath.FromSlash("testdata/imports")})
+	i.Use(stdlib.Symbols)
+
+	src := `import (
+	"fmt"
+
+	"guthib.com/lib"
+)
+
+func main() { fmt.Println(lib.Upper("hello")) }`
+	imports, err := i.Imports(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"fmt", "guthib.com/lib", "guthib.com/util", "strings"}; !reflect.DeepEqual(imports, want) {
+		t.Errorf("got %v, want %v", imports, want)
+	}
+
+	// Nothing has been imported or evaluated.
+	if _, err := i.Eval("lib.Upper"); err == nil {
+		t.Error("expected an undefined lib error")
+	}
+
+	if _, err := i.Imports(`import "guthib.com/missing"`); err == nil {
+		t.Error("expected an error for a missing package")
+	}
+}
+
+func TestEvalGoPathList(t *testing.T) {
+	// The package is only found in the second GOPATH entry.
+	goPath := filepath.Join("testdata", "dir") + string(os.PathListSeparator) + filepath.Join("testdata", "imports")
+	i := interp.New(interp.Options{GoPath: goPath})
+	i.Use(stdlib.Symbols)
+
+	eval(t, i, `import "guthib.com/lib"`)
+	if v := eval(t, i, `lib.Upper(" hello ")`); v.Interface() != "HELLO" {
+		t.Errorf("got %v, want HELLO", v)
+	}
+	if _, err := i.Eval(`import "guthib.com/missing"`); err == nil {
+		t.Error("expected an error for a missing package")
+	}
+}
+
+func TestRunTests(t *testing.T) {
+	i := interp.New(interp.Options{Stderr: ioutil.Discard})
+	i.Use(stdlib.Symbols)
+	results, err := i.RunTests(`package foo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func add(a, b int) int { return a + b }
+
+func TestAdd(t *testing.T) {
+	if got := add(1, 2); got != 3 {
+		t.Errorf("got %d", got)
+	}
+	t.Log("added", 1, 2)
+}
+
+func TestFatal(t *testing.T) {
+	defer t.Log("deferred")
+	t.Cleanup(func() { t.Log("cleanup") })
+	t.Fatalf("fatal: %v", errors.New("e"))
+	t.Log("not reached")
+}
+
+func TestSub(t *testing.T) {
+	for _, n := range []int{1, 2} {
+		n := n
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			if n == 2 {
+				t.Error("bad", t.Name())
+			}
+		})
+	}
+}
+
+func TestPanic(t *testing.T) { panic("boom") }
+
+func TestSkip(t *testing.T) { t.Skip("later") }
+
+func Testing(t *testing.T) { t.Fail() }
+
+func helper(t *testing.T) { t.Fail() }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res []string
+	for _, r := range results {
+		s := r.Name
+		if r.Failed {
+			s += " FAIL"
+		}
+		if r.Skipped {
+			s += " SKIP"
+		}
+		res = append(res, fmt.Sprintf("%s %q", s, r.Output))
+	}
+	want := []string{
+		`TestAdd ["added 1 2"]`,
+		`TestFatal FAIL ["fatal: e" "deferred" "cleanup"]`,
+		`TestSub FAIL []`,
+		`TestSub/1 []`,
+		`TestSub/2 FAIL ["bad TestSub/2"]`,
+		`TestPanic FAIL ["panic: boom"]`,
+		`TestSkip SKIP ["later"]`,
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("got %q, want %q", res, want)
+	}
+
+	if _, err := i.RunTests("func TestBad(i int) {}"); err == nil || err.Error() != "wrong signature for TestBad, must be: func TestBad(t *testing.T)" {
+		t.Errorf("got error %v", err)
+	}
 }
 
 func TestEvalBinCall(t *testing.T) {
@@ -721,6 +1998,230 @@ func TestEvalWithContext(t *testing.T) {
 	}
 }
 
+func TestEvalWithContextSelect(t *testing.T) {
+	exited := make(chan string, 4)
+	i := interp.New(interp.Options{})
+	i.Use(interp.Exports{"host/host": {"Exit": reflect.ValueOf(func(s string) { exited <- s })}})
+	eval(t, i, `import "host/host"`)
+	eval(t, i, `
+func wait(name string, c chan int) {
+	defer host.Exit(name)
+	switch name {
+	case "empty":
+		select {}
+	case "main", "recv":
+		select {
+		case <-c:
+		}
+	case "send":
+		select {
+		case <-c:
+		case c <- 1:
+		}
+	}
+}
+`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := i.EvalWithContext(ctx, `
+c := make(chan int)
+go wait("empty", c)
+go wait("recv", c)
+go wait("send", make(chan int))
+wait("main", c)
+`)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	var names []string
+	for len(names) < 4 {
+		select {
+		case name := <-exited:
+			names = append(names, name)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for blocked selects, got %v", names)
+		}
+	}
+	sort.Strings(names)
+	if s := strings.Join(names, " "); s != "empty main recv send" {
+		t.Errorf("got %s", s)
+	}
+}
+
+func TestEvalWithContextStdin(t *testing.T) {
+	pin, pout := io.Pipe()
+	defer func() {
+		_ = pin.Close()
+		_ = pout.Close()
+	}()
+	var out safeBuffer
+	out.buf = &bytes.Buffer{}
+	i := interp.New(interp.Options{Stdin: pin, Stdout: &out})
+	i.Use(stdlib.Symbols)
+	eval(t, i, `import ("bufio"; "fmt"; "os")`)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			if _, err := pout.Write([]byte("hello\n")); err != nil {
+				t.Error(err)
+			}
+			Sleep(100 * time.Millisecond)
+			cancel()
+		}()
+		_, err := i.EvalWithContext(ctx, `
+			s := bufio.NewScanner(os.Stdin)
+			for s.Scan() {
+				fmt.Println("got", s.Text())
+			}`)
+		if err != context.Canceled {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	}()
+	select {
+	case <-time.After(time.Second):
+		t.Fatal("timeout failed to cancel stdin read")
+	case <-done:
+	}
+
+	if res, want := out.String(), "got hello\n"; res != want {
+		t.Errorf("got %q, want %q", res, want)
+	}
+
+	// Input is not lost by the interrupted read. Give time to the cancelled
+	// evaluation to terminate before starting a new one.
+	Sleep(100 * time.Millisecond)
+	go func() {
+		if _, err := pout.Write([]byte("world\n")); err != nil {
+			t.Error(err)
+		}
+	}()
+	v, err := i.EvalWithContext(context.Background(), `
+		s2 := bufio.NewScanner(os.Stdin)
+		s2.Scan()
+		s2.Text()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, want := v.Interface(), "world"; res != want {
+		t.Errorf("got %q, want %q", res, want)
+	}
+}
+
+func TestEvalWithContextReuse(t *testing.T) {
+	i := interp.New(interp.Options{})
+	src := `r := make(chan int); go func() { for j := 0; j < 100000; j++ {}; r <- 2 }(); <-r`
+
+	for k := 0; k < 2; k++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err := i.EvalWithContext(ctx, `c := make(chan int); <-c`)
+		cancel()
+		if err != context.DeadlineExceeded {
+			t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+		}
+
+		// Channel operations are not interrupted by the previous cancellation.
+		if v := eval(t, i, src); v.Interface() != 2 {
+			t.Fatalf("got %v, want 2", v)
+		}
+		v, err := i.EvalWithContext(context.Background(), src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Interface() != 2 {
+			t.Fatalf("got %v, want 2", v)
+		}
+	}
+}
+
+func TestEvalPathDir(t *testing.T) {
+	dir := filepath.Join("testdata", "dir")
+
+	var out bytes.Buffer
+	i := interp.New(interp.Options{Stdout: &out, BuildTags: []string{"yaegi_skip"}})
+	i.Use(stdlib.Symbols)
+	if _, err := i.EvalPath(dir); err != nil {
+		t.Fatal(err)
+	}
+	if res, want := out.String(), "42 skip\n"; res != want {
+		t.Errorf("got %q, want %q", res, want)
+	}
+	if _, ok := i.Symbols("")["TestCompute"]; ok {
+		t.Error("test file should not be evaluated")
+	}
+
+	out.Reset()
+	i = interp.New(interp.Options{Stdout: &out})
+	i.Use(stdlib.Symbols)
+	if _, err := i.EvalTest(dir); err != nil {
+		t.Fatal(err)
+	}
+	if res, want := out.String(), "42 dir\n"; res != want {
+		t.Errorf("got %q, want %q", res, want)
+	}
+	var testCompute func() string
+	v, err := i.EvalFunc("TestCompute", testCompute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := v.Interface().(func() string)(); res != "compute 42" {
+		t.Errorf("got %q, want %q", res, "compute 42")
+	}
+}
+
+func TestEvalPathWithContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yaegi-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "main.go")
+	src := `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("start")
+	for {
+	}
+}`
+	if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out safeBuffer
+	out.buf = &bytes.Buffer{}
+	i := interp.New(interp.Options{Stdout: &out})
+	i.Use(stdlib.Symbols)
+
+	// A context already done prevents the evaluation.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := i.EvalPathWithContext(ctx, path); err != context.Canceled {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+	if res := out.String(); res != "" {
+		t.Fatalf("got output %q", res)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := i.EvalPathWithContext(ctx, path); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if res := out.String(); res != "start\n" {
+		t.Fatalf("got output %q", res)
+	}
+
+	if _, err := i.EvalPathWithContext(context.Background(), filepath.Join(dir, "missing.go")); !os.IsNotExist(err) {
+		t.Fatalf("got %v, want not exist error", err)
+	}
+}
+
 func runTests(t *testing.T, i *interp.Interpreter, tests []testCase) {
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
@@ -1111,6 +2612,125 @@ func TestConcurrentEvals3(t *testing.T) {
 	}
 }
 
+func TestREPLCommands(t *testing.T) {
+	src := `import str "strings"
+type T struct{ A int }
+var a = str.Repeat("a", 2)
+func f(i int) string { return "" }
+:vars
+:imports
+:foo
+`
+	var stdout, stderr bytes.Buffer
+	i := interp.New(interp.Options{Stdin: strings.NewReader(src), Stdout: &stdout, Stderr: &stderr})
+	i.Use(stdlib.Symbols)
+	if _, err := i.REPL(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `func f func(int)(string)
+type T struct{A int;}
+var a string
+str "strings"
+`
+	if res := stdout.String(); res != want {
+		t.Errorf("got %q, want %q", res, want)
+	}
+	if res, want := stderr.String(), "unknown command :foo, want :vars, :imports, :save or :load\n"; res != want {
+		t.Errorf("got %q, want %q", res, want)
+	}
+}
+
+func TestREPLOnResult(t *testing.T) {
+	src := `a := 1
+type T struct {
+}
+a + 2
+undefined
+`
+	var srcs []string
+	var errs int
+	var res reflect.Value
+	onResult := func(src string, v reflect.Value, err error) {
+		srcs = append(srcs, src)
+		if err != nil {
+			errs++
+		}
+		if src == "a + 2\n" {
+			res = v
+		}
+	}
+	i := interp.New(interp.Options{Stdin: strings.NewReader(src), Stdout: ioutil.Discard, Stderr: ioutil.Discard, OnResult: onResult})
+	_, _ = i.REPL()
+
+	want := []string{"a := 1\n", "type T struct {\n}\n", "a + 2\n", "undefined\n"}
+	if !reflect.DeepEqual(srcs, want) {
+		t.Errorf("got %q, want %q", srcs, want)
+	}
+	if errs != 1 {
+		t.Errorf("got %d errors, want 1", errs)
+	}
+	if !res.IsValid() || res.Interface() != 3 {
+		t.Errorf("got result %v, want 3", res)
+	}
+}
+
+func TestREPLSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yaegi-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "session.go")
+
+	repl := func(src string) (string, string) {
+		var stdout, stderr bytes.Buffer
+		i := interp.New(interp.Options{Stdin: strings.NewReader(src), Stdout: &stdout, Stderr: &stderr})
+		i.Use(stdlib.Symbols)
+		_, _ = i.REPL()
+		return stdout.String(), stderr.String()
+	}
+
+	_, errs := repl("a := 2\nfunc f(i int) int {\n\treturn i * 3\n}\nb := undefined()\n:save " + file + "\n")
+	if errs == "" {
+		t.Fatal("expected an error")
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, want := string(b), "a := 2\nfunc f(i int) int {\n\treturn i * 3\n}\n"; res != want {
+		t.Errorf("got %q, want %q", res, want)
+	}
+
+	out, errs := repl(":load " + file + "\nprintln(f(a))\n")
+	if errs != "" {
+		t.Fatal(errs)
+	}
+	if out != "6\n" {
+		t.Errorf("got output %q, want %q", out, "6\n")
+	}
+
+	if err := ioutil.WriteFile(file, []byte("c := 1\nd := c + \"x\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, errs = repl(":load " + file + "\n")
+	if want := file + ":2: 1:33: invalid operation: mismatched types int and string\n"; errs != want {
+		t.Errorf("got %q, want %q", errs, want)
+	}
+	// REPL commands are executed, not evaluated as Go code.
+	if err := ioutil.WriteFile(file, []byte("c := 1\n:vars\n:foo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, errs = repl(":load " + file + "\n")
+	if out != "var c int\n" {
+		t.Errorf("got output %q, want %q", out, "var c int\n")
+	}
+	if want := file + ":3: unknown command :foo, want :vars, :imports, :save or :load\n"; errs != want {
+		t.Errorf("got %q, want %q", errs, want)
+	}
+}
+
 func TestEvalScanner(t *testing.T) {
 	type testCase struct {
 		desc      string
@@ -1,7 +1,10 @@
 package interp
 
 import (
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -188,3 +191,58 @@ func TestIsNatural(t *testing.T) {
 		}
 	}
 }
+
+func TestCompileCache(t *testing.T) {
+	i := New(Options{CompileCache: true})
+	eval := func(src string) reflect.Value {
+		t.Helper()
+		res, err := i.Eval(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	eval("var n int; func f() int { return n * 10 }")
+	for k := 1; k <= 3; k++ {
+		if res := eval("n++; f()"); res.Interface() != k*10 {
+			t.Fatalf("got %v, want %d", res, k*10)
+		}
+	}
+	if l := len(i.cache); l != 2 {
+		t.Fatalf("got %d cache entries, want 2", l)
+	}
+
+	// A new declaration invalidates previous compilations.
+	eval("func g() int { return n }")
+	if res := eval("n++; f()"); res.Interface() != 40 {
+		t.Fatalf("got %v, want 40", res)
+	}
+	if l := len(i.cache); l != 4 {
+		t.Fatalf("got %d cache entries, want 4", l)
+	}
+
+	// So does the use of new binary symbols.
+	i.Use(Exports{"foo": {"Bar": reflect.ValueOf(1)}})
+	eval("n++; f()")
+	if l := len(i.cache); l != 5 {
+		t.Fatalf("got %d cache entries, want 5", l)
+	}
+
+	// And the evaluation of a package directory.
+	dir, err := ioutil.TempDir("", "yaegi-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "g.go"), []byte("package main\n\nfunc h() int { return n }\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = i.EvalPath(dir); err != nil {
+		t.Fatal(err)
+	}
+	eval("n++; f()")
+	if l := len(i.cache); l != 6 {
+		t.Fatalf("got %d cache entries, want 6", l)
+	}
+}
@@ -3,10 +3,17 @@ package interp
 //go:generate go run ../internal/genop/genop.go
 
 import (
+	"bytes"
 	"fmt"
 	"go/constant"
+	"go/token"
 	"log"
 	"reflect"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -92,6 +99,7 @@ func (interp *Interpreter) run(n *node, cf *frame) {
 		f = interp.frame
 	} else {
 		f = newFrame(cf, len(n.types), interp.runid())
+		f.def = n
 	}
 	interp.mutex.RLock()
 	c := reflect.ValueOf(interp.done)
@@ -107,6 +115,14 @@ func (interp *Interpreter) run(n *node, cf *frame) {
 	runCfg(n.start, f)
 }
 
+// callDeferred calls the deferred function val[0] with arguments val[1:],
+// and returns the value of a panic occurring in the call, or nil.
+func callDeferred(val []reflect.Value) (r interface{}) {
+	defer func() { r = recover() }()
+	val[0].Call(val[1:])
+	return nil
+}
+
 // Functions set to run during execution of CFG.
 
 // runCfg executes a node AST by walking its CFG and running node builtin at each step.
@@ -114,18 +130,51 @@ func runCfg(n *node, f *frame) {
 	defer func() {
 		f.mutex.Lock()
 		f.recovered = recover()
+		if f.recovered != nil && f.stack == nil {
+			// The exact location of a runtime panic is unknown.
+			f.stack = f.callStack(n)
+		}
+		// As os.Exit, an interpreted exit does not run deferred functions.
+		_, exit := f.recovered.(ExitError)
 		for _, val := range f.deferred {
-			val[0].Call(val[1:])
+			if exit {
+				break
+			}
+			// A panic in a deferred function replaces the current one,
+			// and the remaining deferred functions are still run.
+			if r := callDeferred(val); r != nil {
+				f.recovered = r
+				_, exit = r.(ExitError)
+			}
 		}
 		if f.recovered != nil {
-			fmt.Println(n.cfgErrorf("panic"))
+			if !exit {
+				fmt.Fprintln(n.interp.stderr, n.cfgErrorf("panic"))
+			}
+			switch {
+			case f.pos != nil:
+				// Pass the panic call stack to the interpreted caller.
+				f.caller.stack = f.stack
+			case f.caller == nil:
+				// Top of the interpreted call stack: keep it for the interpreter.
+				n.interp.mutex.Lock()
+				n.interp.panicked = f.stack
+				n.interp.mutex.Unlock()
+				f.stack = nil
+			}
 			f.mutex.Unlock()
 			panic(f.recovered)
 		}
+		f.stack = nil
 		f.mutex.Unlock()
 	}()
 
-	for exec := n.exec; exec != nil && f.runid() == n.interp.runid(); {
+	interp := n.interp
+	for exec := n.exec; exec != nil && f.runid() == interp.runid(); {
+		if interp.maxSteps > 0 && atomic.AddUint64(&interp.steps, 1) > interp.maxSteps {
+			// Step budget exhausted: all executions stop at their next step.
+			break
+		}
 		exec = exec(f)
 	}
 }
@@ -146,9 +195,10 @@ func typeAssertStatus(n *node) {
 			return next
 		}
 	case isInterface(c1.typ):
+		elem := genValueInterfaceElem(c0)
 		n.exec = func(f *frame) bltn {
-			v := value(f)
-			ok := v.IsValid() && canAssertTypes(v.Elem().Type(), rtype)
+			v := elem(f)
+			ok := v.IsValid() && canAssertTypes(v.Type(), rtype)
 			value1(f).SetBool(ok)
 			return next
 		}
@@ -192,8 +242,9 @@ func typeAssert(n *node) {
 			return next
 		}
 	case isInterface(c1.typ):
+		elem := genValueInterfaceElem(c0)
 		n.exec = func(f *frame) bltn {
-			v := value(f).Elem()
+			v := elem(f)
 			typ := value0(f).Type()
 			if !v.IsValid() {
 				panic(fmt.Sprintf("interface conversion: interface {} is nil, not %s", typ.String()))
@@ -242,15 +293,14 @@ func typeAssert2(n *node) {
 	value1 := genValue(n.anc.child[1])       // returned status
 	setStatus := n.anc.child[1].ident != "_" // do not assign status to "_"
 	typ := c1.typ                            // type to assert or convert to
-	typID := typ.id()
-	rtype := typ.rtype // type to assert
+	rtype := typ.rtype                       // type to assert
 	next := getExec(n.tnext)
 
 	switch {
 	case isInterfaceSrc(typ):
 		n.exec = func(f *frame) bltn {
 			v, ok := value(f).Interface().(valueInterface)
-			if ok && v.node.typ.id() == typID {
+			if ok && v.node != nil && v.node.typ.cat != nilT && v.node.typ.implements(typ) {
 				value0(f).Set(value(f))
 			} else {
 				ok = false
@@ -261,8 +311,9 @@ func typeAssert2(n *node) {
 			return next
 		}
 	case isInterface(typ):
+		elem := genValueInterfaceElem(c0)
 		n.exec = func(f *frame) bltn {
-			v := value(f).Elem()
+			v := elem(f)
 			ok := v.IsValid() && canAssertTypes(v.Type(), rtype)
 			if ok {
 				value0(f).Set(v)
@@ -365,12 +416,43 @@ func convert(n *node) {
 		return
 	}
 
+	if l := arrayLen(typ); l >= 0 && c.typ.TypeOf().Kind() == reflect.Slice {
+		// Slice to array or array pointer conversion, check the slice length.
+		n.exec = func(f *frame) bltn {
+			v := value(f)
+			if v.Len() < l {
+				panic(runtimeError(fmt.Sprintf("cannot convert slice with length %d to array or pointer to array with length %d", v.Len(), l)))
+			}
+			dest(f).Set(v.Convert(typ))
+			return next
+		}
+		return
+	}
+
 	n.exec = func(f *frame) bltn {
 		dest(f).Set(value(f).Convert(typ))
 		return next
 	}
 }
 
+// arrayLen returns the length of the array or array pointer type t, or -1.
+func arrayLen(t reflect.Type) int {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Array {
+		return -1
+	}
+	return t.Len()
+}
+
+// runtimeError is a run-time panic detected by the interpreter itself.
+type runtimeError string
+
+func (e runtimeError) RuntimeError() {}
+
+func (e runtimeError) Error() string { return "runtime error: " + string(e) }
+
 func isRecursiveType(t *itype, rtype reflect.Type) bool {
 	if t.cat == structT && rtype.Kind() == reflect.Interface {
 		return true
@@ -458,6 +540,10 @@ func assign(n *node) {
 				t = reflect.TypeOf((*node)(nil))
 			case interfaceT:
 				t = reflect.TypeOf((*valueInterface)(nil)).Elem()
+			case nilT:
+				if d := n.child[i]; d.ident != "_" {
+					t = d.typ.frameType()
+				}
 			default:
 				t = typ.TypeOf()
 			}
@@ -593,24 +679,185 @@ func _recover(n *node) {
 	dest := genValue(n)
 
 	n.exec = func(f *frame) bltn {
-		if f.anc.recovered == nil {
+		// Only a function directly deferred by a panicking function, thus
+		// not called from interpreted code, can recover.
+		if f.pos != nil || f.caller == nil || f.caller.recovered == nil {
 			dest(f).Set(reflect.ValueOf(valueInterface{}))
+			return tnext
+		}
+		// The recovered value is either a reflect.Value from an interpreted
+		// panic call, or a raw value from a runtime or binary code panic.
+		v, ok := f.caller.recovered.(reflect.Value)
+		if !ok {
+			v = reflect.ValueOf(f.caller.recovered)
+		}
+		f.caller.recovered = nil
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if _, ok := v.Interface().(valueInterface); ok {
+			dest(f).Set(v)
 		} else {
-			dest(f).Set(reflect.ValueOf(valueInterface{n, reflect.ValueOf(f.anc.recovered)}))
-			f.anc.recovered = nil
+			dest(f).Set(reflect.ValueOf(valueInterface{n, v}))
 		}
 		return tnext
 	}
 }
 
+// panicNilError is the value recovered from panic(nil), as runtime.PanicNilError since Go 1.21.
+type panicNilError struct{}
+
+func (*panicNilError) Error() string {
+	return "panic called with nil argument (use -d=panicnil=1 to disable)"
+}
+
+func (*panicNilError) RuntimeError() {}
+
 func _panic(n *node) {
 	value := genValue(n.child[1])
 
 	n.exec = func(f *frame) bltn {
-		panic(value(f))
+		v := value(f)
+		f.stack = f.callStack(n)
+		if isNilValue(v) {
+			panic(reflect.ValueOf(error(&panicNilError{})))
+		}
+		panic(v)
 	}
 }
 
+// isNilValue returns true if v is an invalid value or a nil interface value.
+func isNilValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	if v.Kind() == reflect.Interface {
+		return v.IsNil()
+	}
+	if vi, ok := v.Interface().(valueInterface); ok {
+		return vi.node == nil || vi.node.typ.cat == nilT || isNilValue(vi.value)
+	}
+	return false
+}
+
+// A stackEntry is an interpreted function call in a call stack.
+type stackEntry struct {
+	def *node // function definition
+	pos *node // current position in function, or nil if unknown
+}
+
+// callStack returns the interpreted call stack from frame f at position pos.
+func (f *frame) callStack(pos *node) []stackEntry {
+	var s []stackEntry
+	for ; f != nil; f = f.caller {
+		if f.def == nil {
+			// Code outside of functions, at package level or in REPL.
+			if pos != nil {
+				s = append(s, stackEntry{nil, pos})
+			}
+			break
+		}
+		s = append(s, stackEntry{f.def, pos})
+		if f.pos == nil && f.caller != nil && f.caller.stack != nil {
+			// Function deferred by a panicking caller: continue from the panic location.
+			return append(s, f.caller.stack...)
+		}
+		pos = f.pos
+	}
+	return s
+}
+
+// formatStack returns the call stack s in a format similar to runtime/debug.Stack.
+func formatStack(s []stackEntry) []byte {
+	var b bytes.Buffer
+	for _, e := range s {
+		if e.def == nil {
+			continue
+		}
+		n := e.pos
+		if n == nil {
+			n = e.def
+		}
+		p := n.interp.fset.Position(n.pos)
+		fmt.Fprintf(&b, "%s(...)\n\t%s:%d\n", funcName(e.def), p.Filename, p.Line)
+	}
+	return b.Bytes()
+}
+
+// stackFrames returns the call stack s as a slice of Frame.
+func stackFrames(s []stackEntry) []Frame {
+	var frames []Frame
+	for _, e := range s {
+		n := e.pos
+		if n == nil {
+			n = e.def
+		}
+		p := n.interp.fset.Position(n.pos)
+		fr := Frame{File: p.Filename, Line: p.Line}
+		if e.def != nil {
+			fr.Function = funcName(e.def)
+		}
+		frames = append(frames, fr)
+	}
+	return frames
+}
+
+// funcName returns the name of the function defined by n, as in Go stack traces.
+func funcName(n *node) string {
+	if n.kind == funcDecl {
+		name := n.child[1].ident
+		if t := defRecvType(n); t != nil {
+			if t.cat == ptrT {
+				name = "(*" + t.val.name + ")." + name
+			} else {
+				name = t.name + "." + name
+			}
+		}
+		return pkgName(n) + "." + name
+	}
+
+	// Function literals are numbered in order of appearance in the enclosing function.
+	p := n.anc
+	for p != nil && p.anc != nil && p.kind != funcDecl && p.kind != funcLit {
+		p = p.anc
+	}
+	var prefix string
+	switch {
+	case p == nil:
+		return pkgName(n) + ".func"
+	case p.kind == funcDecl:
+		prefix = funcName(p) + ".func"
+	case p.kind == funcLit:
+		prefix = funcName(p) + "."
+	default:
+		prefix = pkgName(n) + ".glob..func"
+	}
+	i, found := 0, false
+	p.Walk(func(c *node) bool {
+		if found || c == p {
+			return !found
+		}
+		if c.kind == funcLit {
+			i++
+			found = c == n
+			return false
+		}
+		return true
+	}, nil)
+	return prefix + strconv.Itoa(i)
+}
+
+// pkgName returns the name of the package where n is defined.
+func pkgName(n *node) string {
+	for n.anc != nil {
+		n = n.anc
+	}
+	if n.kind == fileStmt && len(n.child) > 0 && n.child[0].kind == identExpr {
+		return n.child[0].ident
+	}
+	return mainID
+}
+
 func genBuiltinDeferWrapper(n *node, in, out []func(*frame) reflect.Value, fn func([]reflect.Value) []reflect.Value) {
 	next := getExec(n.tnext)
 
@@ -657,29 +904,45 @@ func genFunctionWrapper(n *node) func(*frame) reflect.Value {
 	if n.kind == basicLit {
 		return func(f *frame) reflect.Value { return n.rval }
 	}
-	if def, ok = n.val.(*node); !ok {
+	if def, ok = n.val.(*node); !ok || n.action == aGetMethod && n.recv == nil && n.findex >= 0 {
+		// Function value computed at run time, including method expressions.
 		return genValueAsFunctionWrapper(n)
 	}
 	start := def.child[3].start
 	numRet := len(def.typ.ret)
 	var rcvr func(*frame) reflect.Value
+	expr := isMethodExpr(n)
 
-	if n.recv != nil {
-		if n.recv.node.typ.cat != defRecvType(def).cat {
+	if n.recv != nil && !expr {
+		switch {
+		case n.recv.node == nil:
+			// The receiver is the dynamic value of an interface.
+			rcvr = genValueRecvDynamic(n.recv, defRecvType(def).cat == ptrT)
+		case n.recv.node.typ.cat != defRecvType(def).cat:
 			rcvr = genValueRecvIndirect(n)
-		} else {
+		default:
 			rcvr = genValueRecv(n)
 		}
 	}
 	funcType := n.typ.TypeOf()
 
 	return func(f *frame) reflect.Value {
+		cf := f             // calling frame, if the function is deferred
 		if n.frame != nil { // Use closure context if defined
 			f = n.frame
 		}
+		var recv reflect.Value
+		if rcvr != nil {
+			// The receiver is bound when the function value is created.
+			recv = bindRecv(rcvr(f), defRecvType(def).cat == ptrT)
+		}
 		return reflect.MakeFunc(funcType, func(in []reflect.Value) []reflect.Value {
 			// Allocate and init local frame. All values to be settable and addressable.
 			fr := newFrame(f, len(def.types), f.runid())
+			fr.def, fr.caller, fr.depth = def, cf, cf.depth+1
+			if max := n.interp.maxCallDepth; max > 0 && fr.depth > max {
+				panic(runtimeError("call depth limit exceeded"))
+			}
 			d := fr.data
 			for i, t := range def.types {
 				d[i] = reflect.New(t).Elem()
@@ -687,7 +950,7 @@ func genFunctionWrapper(n *node) func(*frame) reflect.Value {
 
 			// Copy method receiver as first argument, if defined
 			if rcvr != nil {
-				src, dest := rcvr(f), d[numRet]
+				src, dest := recv, d[numRet]
 				if src.Type().Kind() != dest.Type().Kind() {
 					dest.Set(src.Addr())
 				} else {
@@ -702,8 +965,20 @@ func genFunctionWrapper(n *node) func(*frame) reflect.Value {
 			for i, arg := range in {
 				typ := def.typ.arg[i]
 				switch {
+				case expr && i == 0:
+					setRecv(d[i], exprRecv(n.recv, arg))
 				case typ.cat == interfaceT:
-					d[i].Set(reflect.ValueOf(valueInterface{value: arg.Elem()}))
+					d[i].Set(hostValueInterface(arg))
+				case typ.cat == variadicT && typ.val.cat == interfaceT:
+					// Convert the host []interface{} to interpreted interface values.
+					if arg.IsNil() {
+						break
+					}
+					v := reflect.MakeSlice(d[i].Type(), arg.Len(), arg.Len())
+					for j := 0; j < arg.Len(); j++ {
+						v.Index(j).Set(hostValueInterface(arg.Index(j)))
+					}
+					d[i].Set(v)
 				case typ.cat == funcT && arg.Kind() == reflect.Func:
 					d[i].Set(reflect.ValueOf(genFunctionNode(arg)))
 				default:
@@ -734,11 +1009,112 @@ func genFunctionNode(v reflect.Value) *node {
 	return &node{kind: funcType, action: aNop, rval: v, typ: &itype{cat: valueT, rtype: v.Type()}}
 }
 
+// fmtInterfaces are the interfaces checked by the fmt printing functions.
+var fmtInterfaces = []string{"fmt.Formatter", "fmt.Stringer"}
+
+// argInterfaces are the binary interfaces checked on an empty interface
+// argument by binary functions, by order of precedence. They are indexed by
+// package path and function or method name. Arguments of other functions are
+// passed unwrapped, to preserve their concrete type.
+var argInterfaces = map[string][]string{
+	"fmt.Errorf":                  fmtInterfaces,
+	"fmt.Fprint":                  fmtInterfaces,
+	"fmt.Fprintf":                 fmtInterfaces,
+	"fmt.Fprintln":                fmtInterfaces,
+	"fmt.Print":                   fmtInterfaces,
+	"fmt.Printf":                  fmtInterfaces,
+	"fmt.Println":                 fmtInterfaces,
+	"fmt.Sprint":                  fmtInterfaces,
+	"fmt.Sprintf":                 fmtInterfaces,
+	"fmt.Sprintln":                fmtInterfaces,
+	"log.Fatal":                   fmtInterfaces,
+	"log.Fatalf":                  fmtInterfaces,
+	"log.Fatalln":                 fmtInterfaces,
+	"log.Panic":                   fmtInterfaces,
+	"log.Panicf":                  fmtInterfaces,
+	"log.Panicln":                 fmtInterfaces,
+	"log.Print":                   fmtInterfaces,
+	"log.Printf":                  fmtInterfaces,
+	"log.Println":                 fmtInterfaces,
+	"encoding/json.Decode":        {"encoding/json.Unmarshaler"},
+	"encoding/json.Encode":        {"encoding/json.Marshaler"},
+	"encoding/json.Marshal":       {"encoding/json.Marshaler"},
+	"encoding/json.MarshalIndent": {"encoding/json.Marshaler"},
+	"encoding/json.Unmarshal":     {"encoding/json.Unmarshaler"},
+}
+
+// argInterface returns the binary interface, checked by the binary function
+// called with argument n, implemented by the interpreted type of n, and its
+// wrapper type, or nil. The corresponding methods can then be exposed through
+// an empty interface.
+func argInterface(n *node) (typ, wrap reflect.Type) {
+	if n.typ.cat == nilT {
+		return nil, nil
+	}
+	ms := n.typ.methods()
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	pkg, name := calleeName(n)
+	for _, s := range argInterfaces[pkg+"."+name] {
+		i := strings.LastIndex(s, ".")
+		p := n.interp.binPkg[s[:i]]
+		v, ok1 := p[s[i+1:]]
+		w, ok2 := p["_"+s[i+1:]]
+		if !ok1 || !ok2 {
+			continue
+		}
+		// The symbol type may be an alias, so the wrapper is looked up by name.
+		t := v.Type().Elem()
+		if ms.contains((&itype{cat: valueT, rtype: t}).methods()) {
+			return t, w.Type().Elem()
+		}
+	}
+	return nil, nil
+}
+
+// calleeName returns the package path and the name of the binary function or
+// method called with argument n, or empty strings.
+func calleeName(n *node) (pkg, name string) {
+	if n.anc == nil || n.anc.kind != callExpr || n.anc.child[0].kind != selectorExpr {
+		return "", ""
+	}
+	c := n.anc.child[0]
+	name = c.child[1].ident
+	switch t := c.child[0].typ; {
+	case t == nil:
+	case t.cat == binPkgT:
+		pkg = t.path
+	case t.cat == valueT:
+		rt := t.rtype
+		if rt.Kind() == reflect.Ptr {
+			rt = rt.Elem()
+		}
+		pkg = rt.PkgPath()
+	}
+	return pkg, name
+}
+
 func genInterfaceWrapper(n *node, typ reflect.Type) func(*frame) reflect.Value {
 	value := genValue(n)
+	var wrap reflect.Type
+	var addr bool
+	if typ != nil && typ.Kind() == reflect.Interface && typ.NumMethod() == 0 && n.typ.cat != valueT {
+		typ, wrap = argInterface(n)
+		// Preserve pointer arguments, for example to be set by json.Unmarshal.
+		addr = n.typ.cat == ptrT
+	}
 	if typ == nil || typ.Kind() != reflect.Interface || typ.NumMethod() == 0 || n.typ.cat == valueT {
 		return value
 	}
+	if isInterfaceSrc(n.typ) {
+		// The concrete type is only known at runtime.
+		return genValueInterfaceWrapper(n, typ)
+	}
+	if n.typ.cat == ptrT && n.typ.val.cat == valueT && n.typ.TypeOf().Implements(typ) {
+		// Pointer to a binary type implementing the interface, no wrapper needed.
+		return value
+	}
 	if nt := n.typ.TypeOf(); nt != nil && nt.Kind() == reflect.Interface {
 		return value
 	}
@@ -754,7 +1130,9 @@ func genInterfaceWrapper(n *node, typ reflect.Type) func(*frame) reflect.Value {
 			_, indexes[i], _, _ = n.typ.lookupBinMethod(names[i])
 		}
 	}
-	wrap := n.interp.getWrapper(typ)
+	if wrap == nil {
+		wrap = n.interp.getWrapper(typ)
+	}
 
 	return func(f *frame) reflect.Value {
 		v := value(f)
@@ -787,10 +1165,41 @@ func genInterfaceWrapper(n *node, typ reflect.Type) func(*frame) reflect.Value {
 			nod.recv = &receiver{n, v, indexes[i]}
 			w.Field(i).Set(genFunctionWrapper(&nod)(f))
 		}
+		if addr {
+			return w.Addr()
+		}
 		return w
 	}
 }
 
+// wrapInterfaceValue returns the interpreted value vi wrapped in the binary
+// interface type typ, so its methods can be called from binary code.
+func wrapInterfaceValue(n *node, f *frame, vi valueInterface, typ reflect.Type) reflect.Value {
+	w := reflect.New(n.interp.getWrapper(typ)).Elem()
+	for i := 0; i < typ.NumMethod(); i++ {
+		name := typ.Method(i).Name
+		m, index := vi.node.typ.lookupMethod(name)
+		if m == nil {
+			// Interpreted method not found, look for binary method, possibly embedded.
+			if r := vi.value.MethodByName(name); r.IsValid() {
+				w.Field(i).Set(r)
+				continue
+			}
+			_, index, _, _ = vi.node.typ.lookupBinMethod(name)
+			o := vi.value
+			if o.Kind() == reflect.Ptr {
+				o = o.Elem()
+			}
+			w.Field(i).Set(o.FieldByIndex(index).MethodByName(name))
+			continue
+		}
+		nod := *m
+		nod.recv = &receiver{nil, vi.value, index}
+		w.Field(i).Set(genFunctionWrapper(&nod)(f))
+	}
+	return w
+}
+
 func call(n *node) {
 	goroutine := n.anc.kind == goStmt
 	var method bool
@@ -811,10 +1220,23 @@ func call(n *node) {
 	}
 	numRet := len(n.child[0].typ.ret)
 	variadic := variadicPos(n)
+	// In f(s...), the slice s is passed as is to the variadic parameter.
+	spread := variadic >= 0 && n.action == aCallSlice
 	child := n.child[1:]
 	tnext := getExec(n.tnext)
 	fnext := getExec(n.fnext)
 
+	// argType returns the type of the i-th input argument.
+	argType := func(i int) *itype {
+		if variadic >= 0 && i >= variadic {
+			if spread {
+				return n.child[0].typ.arg[variadic]
+			}
+			return n.child[0].typ.arg[variadic].val
+		}
+		return n.child[0].typ.arg[i]
+	}
+
 	// Compute input argument value functions.
 	for i, c := range child {
 		switch {
@@ -823,21 +1245,32 @@ func call(n *node) {
 			numOut := c.child[0].typ.rtype.NumOut()
 			for j := 0; j < numOut; j++ {
 				ind := c.findex + j
+				if isInterfaceSrc(argType(i + j)) {
+					// Wrap binary value in an interpreted interface.
+					values = append(values, func(f *frame) reflect.Value {
+						v := f.data[ind]
+						if v.Kind() == reflect.Interface && v.IsNil() {
+							return zeroInterfaceValue()
+						}
+						return reflect.ValueOf(valueInterface{c, v})
+					})
+					continue
+				}
 				values = append(values, func(f *frame) reflect.Value { return f.data[ind] })
 			}
 		case isRegularCall(c):
 			// Arguments are return values of a nested function call.
-			for j := range c.child[0].typ.ret {
+			for j, ret := range c.child[0].typ.ret {
 				ind := c.findex + j
+				if isInterfaceSrc(argType(i+j)) && !isInterfaceSrc(ret) {
+					// Wrap concrete value in an interpreted interface.
+					values = append(values, func(f *frame) reflect.Value { return reflect.ValueOf(valueInterface{c, f.data[ind]}) })
+					continue
+				}
 				values = append(values, func(f *frame) reflect.Value { return f.data[ind] })
 			}
 		default:
-			var arg *itype
-			if variadic >= 0 && i >= variadic {
-				arg = n.child[0].typ.arg[variadic].val
-			} else {
-				arg = n.child[0].typ.arg[i]
-			}
+			arg := argType(i)
 			if c.kind == basicLit || c.rval.IsValid() {
 				argType := arg.TypeOf()
 				convertLiteralValue(c, argType)
@@ -845,6 +1278,9 @@ func call(n *node) {
 			switch {
 			case arg.cat == interfaceT:
 				values = append(values, genValueInterface(c))
+			case arg.cat == valueT && arg.rtype.Kind() == reflect.Interface:
+				// Wrap interpreted value to implement the binary interface.
+				values = append(values, genInterfaceWrapper(c, arg.rtype))
 			case isRecursiveType(c.typ, c.typ.rtype):
 				values = append(values, genValueRecursiveInterfacePtrValue(c))
 			default:
@@ -856,6 +1292,8 @@ func call(n *node) {
 	// Compute output argument value functions.
 	rtypes := n.child[0].typ.ret
 	rvalues := make([]func(*frame) reflect.Value, len(rtypes))
+	// Output values to wrap in a binary interface after the call.
+	var wrapped []func(*frame, []reflect.Value)
 	switch n.anc.kind {
 	case defineXStmt, assignXStmt:
 		for i := range rvalues {
@@ -865,6 +1303,10 @@ func call(n *node) {
 				// Skip assigning return value to blank var.
 			case c.typ.cat == interfaceT && rtypes[i].cat != interfaceT:
 				rvalues[i] = genValueInterfaceValue(c)
+			case isBinInterface(c.typ) && rtypes[i].cat != valueT:
+				// The returned interpreted value must be wrapped to implement
+				// the destination interface: keep it in the callee frame.
+				wrapped = append(wrapped, genWrapOutput(n, c, i, rtypes[i]))
 			default:
 				rvalues[i] = genValue(c)
 			}
@@ -925,9 +1367,13 @@ func call(n *node) {
 
 		// Call bin func if defined
 		if bf.IsValid() {
-			in := make([]reflect.Value, len(values))
-			for i, v := range values {
-				in[i] = v(f)
+			in := make([]reflect.Value, 0, len(values))
+			for _, v := range values {
+				if v == nil {
+					// Skip the interface method receiver, already bound to bf.
+					continue
+				}
+				in = append(in, v(f))
 			}
 			if goroutine {
 				go bf.Call(in)
@@ -951,6 +1397,13 @@ func call(n *node) {
 			anc = def.frame
 		}
 		nf := newFrame(anc, len(def.types), anc.runid())
+		nf.def = def
+		if !goroutine {
+			nf.caller, nf.pos, nf.depth = f, n, f.depth+1
+		}
+		if max := n.interp.maxCallDepth; max > 0 && nf.depth > max {
+			panic(runtimeError("call depth limit exceeded"))
+		}
 		var vararg reflect.Value
 
 		// Init return values
@@ -967,53 +1420,50 @@ func call(n *node) {
 			nf.data[numRet+i] = reflect.New(t).Elem()
 		}
 
+		// A method value with a bound receiver may be called as a function.
+		bound := !method && def.recv != nil && def.recv.val.IsValid()
+		// A method expression takes the receiver as first argument.
+		expr := !method && isMethodExpr(def)
+
 		// Init variadic argument vector
 		varIndex := variadic
 		if variadic >= 0 {
-			if method {
+			switch {
+			case method:
 				vararg = nf.data[numRet+variadic+1]
 				varIndex++
-			} else {
+			case bound:
+				vararg = nf.data[numRet+variadic+1]
+			default:
 				vararg = nf.data[numRet+variadic]
 			}
 		}
 
 		// Copy input parameters from caller
 		if dest := nf.data[numRet:]; len(dest) > 0 {
+			if bound {
+				setRecv(dest[0], recvValue(def.recv))
+				dest = dest[1:]
+			}
 			for i, v := range values {
 				switch {
 				case method && i == 0:
 					// compute receiver
-					var src reflect.Value
-					if v == nil {
-						src = def.recv.val
-						if len(def.recv.index) > 0 {
-							if src.Kind() == reflect.Ptr {
-								src = src.Elem().FieldByIndex(def.recv.index)
-							} else {
-								src = src.FieldByIndex(def.recv.index)
-							}
-						}
+					if v == nil || def.recv != nil && def.recv.val.IsValid() {
+						// Dynamic or bound receiver.
+						setRecv(dest[0], recvValue(def.recv))
 					} else {
-						src = v(f)
+						setRecv(dest[0], v(f))
 					}
-					// Accommodate to receiver type
-					d := dest[0]
-					if ks, kd := src.Kind(), d.Kind(); ks != kd {
-						if kd == reflect.Ptr {
-							d.Set(src.Addr())
-						} else {
-							d.Set(src.Elem())
-						}
-					} else {
-						d.Set(src)
+				case expr && i == 0:
+					setRecv(dest[0], exprRecv(def.recv, v(f)))
+				case spread && i == varIndex:
+					// A nil slice literal leaves the variadic parameter nil.
+					if val := v(f); val.Type() == vararg.Type() {
+						vararg.Set(val)
 					}
 				case variadic >= 0 && i >= varIndex:
-					if v(f).Type() == vararg.Type() {
-						vararg.Set(v(f))
-					} else {
-						vararg.Set(reflect.Append(vararg, v(f)))
-					}
+					vararg.Set(reflect.Append(vararg, v(f)))
 				default:
 					val := v(f)
 					if !val.IsZero() {
@@ -1030,6 +1480,10 @@ func call(n *node) {
 		}
 		runCfg(def.child[3].start, nf)
 
+		for _, w := range wrapped {
+			w(f, nf.data)
+		}
+
 		// Handle branching according to boolean result
 		if fnext != nil && !nf.data[0].Bool() {
 			return fnext
@@ -1038,6 +1492,63 @@ func call(n *node) {
 	}
 }
 
+// isBinInterface returns true if t is a binary interface type.
+func isBinInterface(t *itype) bool {
+	return t.cat == valueT && t.rtype != nil && t.rtype.Kind() == reflect.Interface
+}
+
+// genWrapOutput returns a function setting the destination c from the i-th
+// output value of the call n, of interpreted type t, wrapped to implement
+// the binary interface type of c.
+func genWrapOutput(n, c *node, i int, t *itype) func(*frame, []reflect.Value) {
+	dest := genValue(c)
+	typ := c.typ.rtype
+	src := &node{kind: basicLit, typ: t, interp: n.interp}
+
+	return func(f *frame, out []reflect.Value) {
+		v := out[i]
+		vi, ok := v.Interface().(valueInterface)
+		if !ok {
+			vi = valueInterface{src, v}
+		}
+		switch {
+		case vi.node == nil || !vi.value.IsValid():
+			dest(f).Set(reflect.Zero(typ))
+		case vi.value.Type().Implements(typ):
+			dest(f).Set(vi.value)
+		default:
+			dest(f).Set(wrapInterfaceValue(n, f, vi, typ))
+		}
+	}
+}
+
+// recvValue returns the receiver value stored in recv.
+func recvValue(recv *receiver) reflect.Value {
+	src := recv.val
+	if len(recv.index) > 0 {
+		if src.Kind() == reflect.Ptr {
+			src = src.Elem().FieldByIndex(recv.index)
+		} else {
+			src = src.FieldByIndex(recv.index)
+		}
+	}
+	return src
+}
+
+// setRecv sets the receiver argument d of a method from src,
+// accommodating to the receiver type.
+func setRecv(d, src reflect.Value) {
+	if ks, kd := src.Kind(), d.Kind(); ks != kd {
+		if kd == reflect.Ptr {
+			d.Set(src.Addr())
+		} else {
+			d.Set(src.Elem())
+		}
+	} else {
+		d.Set(src)
+	}
+}
+
 // pindex returns definition parameter index for function call.
 func pindex(i, variadic int) int {
 	if variadic < 0 || i <= variadic {
@@ -1067,6 +1578,19 @@ func callBin(n *node) {
 	fnext := getExec(n.fnext)
 	child := n.child[1:]
 	value := genValue(n.child[0])
+	if fn := n.child[0].rval; fn.IsValid() && fn.Kind() == reflect.Func {
+		// Report the interpreted call stack instead of the interpreter one.
+		switch fn.Pointer() {
+		case reflect.ValueOf(debug.Stack).Pointer():
+			value = func(f *frame) reflect.Value {
+				return reflect.ValueOf(func() []byte { return formatStack(f.callStack(n)) })
+			}
+		case reflect.ValueOf(debug.PrintStack).Pointer():
+			value = func(f *frame) reflect.Value {
+				return reflect.ValueOf(func() { _, _ = n.interp.stderr.Write(formatStack(f.callStack(n))) })
+			}
+		}
+	}
 	var values []func(*frame) reflect.Value
 	funcType := n.child[0].typ.rtype
 	variadic := -1
@@ -1076,7 +1600,9 @@ func callBin(n *node) {
 	// A method signature obtained from reflect.Type includes receiver as 1st arg, except for interface types.
 	rcvrOffset := 0
 	if recv := n.child[0].recv; recv != nil && !isInterface(recv.node.typ) {
-		if funcType.NumIn() > len(child) {
+		// The receiver type is set for a method of a concrete binary type,
+		// the argument count can not be relied on for a variadic method.
+		if n.child[0].typ.recv != nil || funcType.NumIn() > len(child) {
 			rcvrOffset = 1
 		}
 	}
@@ -1088,7 +1614,13 @@ func callBin(n *node) {
 	}
 
 	for i, c := range child {
-		defType := funcType.In(rcvrOffset + pindex(i, variadic))
+		var defType reflect.Type
+		if variadic >= 0 && rcvrOffset+i >= variadic && n.action != aCallSlice {
+			// Variadic argument: use the type of slice element.
+			defType = funcType.In(variadic).Elem()
+		} else {
+			defType = funcType.In(rcvrOffset + pindex(i, variadic))
+		}
 		switch {
 		case isBinCall(c):
 			// Handle nested function calls: pass returned values as arguments
@@ -1121,7 +1653,11 @@ func callBin(n *node) {
 			case funcT:
 				values = append(values, genFunctionWrapper(c))
 			case interfaceT:
-				values = append(values, genValueInterfaceValue(c))
+				if defType.Kind() == reflect.Interface && defType.NumMethod() > 0 {
+					values = append(values, genValueInterfaceWrapper(c, defType))
+				} else {
+					values = append(values, genValueInterfaceValue(c))
+				}
 			case arrayT, variadicT:
 				switch c.typ.val.cat {
 				case interfaceT:
@@ -1129,6 +1665,12 @@ func callBin(n *node) {
 				default:
 					values = append(values, genInterfaceWrapper(c, defType))
 				}
+			case mapT:
+				if isInterfaceSrc(c.typ.key) || isInterfaceSrc(c.typ.val) {
+					values = append(values, genValueInterfaceMap(c))
+				} else {
+					values = append(values, genInterfaceWrapper(c, defType))
+				}
 			default:
 				values = append(values, genInterfaceWrapper(c, defType))
 			}
@@ -1241,6 +1783,20 @@ func getIndexBinMethod(n *node) {
 	value := genValue(n.child[0])
 	next := getExec(n.tnext)
 
+	if t := n.child[0].typ.TypeOf(); t != nil && t.Kind() == reflect.Interface {
+		// The method of an interface value is resolved when called: bind it to
+		// a copy of the interface, so a later change of the variable is ignored,
+		// as for deferred calls.
+		n.exec = func(f *frame) bltn {
+			v := value(f)
+			r := reflect.New(v.Type()).Elem()
+			r.Set(v)
+			getFrame(f, l).data[i] = r.Method(m)
+			return next
+		}
+		return
+	}
+
 	n.exec = func(f *frame) bltn {
 		// Can not use .Set() because dest type contains the receiver and source not
 		// dest(f).Set(value(f).Method(m))
@@ -1415,6 +1971,10 @@ func getIndexMap2(n *node) {
 		nop(n)
 		return
 	}
+	var z reflect.Value // zero value of result, for missing keys
+	if doValue {
+		z = reflect.New(typ.frameType()).Elem()
+	}
 	if n.child[1].rval.IsValid() { // constant map index
 		mi := n.child[1].rval
 		switch {
@@ -1433,6 +1993,8 @@ func getIndexMap2(n *node) {
 					} else {
 						dest(f).Set(reflect.ValueOf(valueInterface{n, e}))
 					}
+				} else {
+					dest(f).Set(z)
 				}
 				if doStatus {
 					value2(f).SetBool(v.IsValid())
@@ -1444,6 +2006,8 @@ func getIndexMap2(n *node) {
 				v := value0(f).MapIndex(mi)
 				if v.IsValid() {
 					dest(f).Set(v)
+				} else {
+					dest(f).Set(z)
 				}
 				if doStatus {
 					value2(f).SetBool(v.IsValid())
@@ -1469,6 +2033,8 @@ func getIndexMap2(n *node) {
 					} else {
 						dest(f).Set(reflect.ValueOf(valueInterface{n, e}))
 					}
+				} else {
+					dest(f).Set(z)
 				}
 				if doStatus {
 					value2(f).SetBool(v.IsValid())
@@ -1480,6 +2046,8 @@ func getIndexMap2(n *node) {
 				v := value0(f).MapIndex(value1(f))
 				if v.IsValid() {
 					dest(f).Set(v)
+				} else {
+					dest(f).Set(z)
 				}
 				if doStatus {
 					value2(f).SetBool(v.IsValid())
@@ -1504,11 +2072,45 @@ func getFunc(n *node) {
 	}
 }
 
+// getClosure is like getFunc, for a function literal capturing variables
+// renewed at each loop iteration. The closure frame has its own copy of the
+// frame data, so it keeps the variables of the iteration which created it.
+func getClosure(n *node) {
+	dest := genValue(n)
+	next := getExec(n.tnext)
+
+	n.exec = func(f *frame) bltn {
+		fr := f.clone()
+		fr.data = append([]reflect.Value(nil), fr.data...)
+		nod := *n
+		nod.val = &nod
+		nod.frame = fr
+		dest(f).Set(reflect.ValueOf(&nod))
+		return next
+	}
+}
+
 func getMethod(n *node) {
 	i := n.findex
 	l := n.level
 	next := getExec(n.tnext)
 
+	if n.anc.kind != callExpr || n.anc.child[0] != n {
+		// Method value: the receiver is evaluated and bound at creation.
+		rcvr := genValueRecv(n)
+		ptr := isPtrRecv(n.val.(*node))
+		n.exec = func(f *frame) bltn {
+			fr := f.clone()
+			nod := *(n.val.(*node))
+			nod.val = &nod
+			nod.recv = &receiver{node: n.recv.node, val: bindRecv(rcvr(f), ptr)}
+			nod.frame = fr
+			getFrame(f, l).data[i] = reflect.ValueOf(&nod)
+			return next
+		}
+		return
+	}
+
 	n.exec = func(f *frame) bltn {
 		fr := f.clone()
 		nod := *(n.val.(*node))
@@ -1520,6 +2122,59 @@ func getMethod(n *node) {
 	}
 }
 
+// getMethodExpr creates the function value of a method expression, where
+// the receiver is the first argument. The receiver argument is adjusted at
+// call, e.g. for (*T).M with a value receiver or a promoted method.
+func getMethodExpr(n *node) {
+	i := n.findex
+	l := n.level
+	next := getExec(n.tnext)
+	_, lind := n.child[0].typ.lookupMethod(n.child[1].ident)
+
+	n.exec = func(f *frame) bltn {
+		nod := *(n.val.(*node))
+		nod.val = &nod
+		nod.typ = n.typ
+		nod.recv = &receiver{index: lind}
+		nod.frame = f.clone()
+		getFrame(f, l).data[i] = reflect.ValueOf(&nod)
+		return next
+	}
+}
+
+// isMethodExpr returns true if def is the function value of a method
+// expression, where the receiver is passed as the first argument.
+func isMethodExpr(def *node) bool {
+	return def.recv != nil && def.recv.node == nil && !def.recv.val.IsValid()
+}
+
+// exprRecv returns the receiver from the first argument v of a method expression.
+func exprRecv(recv *receiver, v reflect.Value) reflect.Value {
+	for _, i := range recv.index {
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// bindRecv returns the receiver r of a method value, as bound at its
+// evaluation: the address of r for a pointer receiver, a copy of r otherwise.
+func bindRecv(r reflect.Value, ptr bool) reflect.Value {
+	switch {
+	case r.Kind() == reflect.Interface:
+		return r
+	case ptr && r.Kind() != reflect.Ptr && r.CanAddr():
+		return r.Addr()
+	case !ptr && r.Kind() == reflect.Ptr:
+		r = r.Elem()
+	}
+	v := reflect.New(r.Type()).Elem()
+	v.Set(r)
+	return v
+}
+
 func getMethodByName(n *node) {
 	next := getExec(n.tnext)
 	value0 := genValue(n.child[0])
@@ -1527,9 +2182,34 @@ func getMethodByName(n *node) {
 	i := n.findex
 	l := n.level
 
+	// The method may be promoted from an interface embedded in a struct.
+	var embedded []int
+	if !isInterfaceSrc(n.child[0].typ) {
+		embedded = n.child[0].typ.embeddedInterface(n.val.([]int))
+	}
+
 	n.exec = func(f *frame) bltn {
-		val := value0(f).Interface().(valueInterface)
+		var val valueInterface
+		if embedded != nil {
+			val = fieldInterface(value0(f), embedded)
+		} else {
+			val = value0(f).Interface().(valueInterface)
+		}
 		m, li := val.node.typ.lookupMethod(name)
+		for m == nil && val.node.typ.cat != valueT {
+			// Look for a method promoted from an embedded interface.
+			seq := val.node.typ.embeddedInterface(val.node.typ.lookupField(name))
+			if seq == nil {
+				break
+			}
+			val = fieldInterface(val.value, seq)
+			m, li = val.node.typ.lookupMethod(name)
+		}
+		if m == nil {
+			// The concrete value is a binary object, use its method.
+			getFrame(f, l).data[i] = reflect.ValueOf(&node{rval: val.value.MethodByName(name)})
+			return next
+		}
 		fr := f.clone()
 		nod := *m
 		nod.val = &nod
@@ -1540,6 +2220,16 @@ func getMethodByName(n *node) {
 	}
 }
 
+// fieldInterface returns the interpreted interface value at field path seq
+// of struct (or pointer to struct) v.
+func fieldInterface(v reflect.Value, seq []int) valueInterface {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	vi, _ := v.FieldByIndex(seq).Interface().(valueInterface)
+	return vi
+}
+
 func getIndexSeq(n *node) {
 	value := genValue(n.child[0])
 	index := n.val.([]int)
@@ -1870,7 +2560,8 @@ func _return(n *node) {
 	case 0:
 		n.exec = nil
 	case 1:
-		if child[0].kind == binaryExpr || isCall(child[0]) {
+		if child[0].kind == binaryExpr && !child[0].rval.IsValid() || isCall(child[0]) {
+			// The result is already computed in the return value location.
 			n.exec = nil
 		} else {
 			v := values[0]
@@ -2085,6 +2776,9 @@ func doCompositeLit(n *node, hasType bool) {
 			values[i] = genValueInterfaceArray(c)
 		case isRecursiveType(typ.field[i].typ, typ.field[i].typ.rtype):
 			values[i] = genValueRecursiveInterface(c, typ.field[i].typ.rtype)
+		case isInterfaceSrc(typ.field[i].typ) && len(typ.field[i].typ.field) > 0:
+			// Keep the concrete type, needed to call interface methods.
+			values[i] = genValueInterface(c)
 		case isInterface(typ.field[i].typ):
 			values[i] = genInterfaceWrapper(c, typ.field[i].typ.rtype)
 		default:
@@ -2092,6 +2786,10 @@ func doCompositeLit(n *node, hasType bool) {
 		}
 	}
 
+	// A literal directly assigned to an existing variable is stored in place,
+	// so the variable location remains shared with closures and pointers.
+	inPlace := n.anc.kind == assignStmt && n.anc.action == aAssign && n.anc.child[0].findex == n.findex && n.anc.child[0].level == n.level
+
 	i := n.findex
 	l := n.level
 	n.exec = func(f *frame) bltn {
@@ -2102,8 +2800,11 @@ func doCompositeLit(n *node, hasType bool) {
 		switch d := value(f); {
 		case d.Type().Kind() == reflect.Ptr:
 			d.Set(a.Addr())
-		case destInterface:
+		case destInterface && d.Type() == valueInterfaceType:
+			// The literal is stored directly in the interface destination.
 			d.Set(reflect.ValueOf(valueInterface{n, a}))
+		case inPlace:
+			d.Set(a)
 		default:
 			getFrame(f, l).data[i] = a
 		}
@@ -2141,6 +2842,9 @@ func doCompositeSparse(n *node, hasType bool) {
 			values[field] = genValueInterfaceArray(c1)
 		case isRecursiveType(typ.field[field].typ, typ.field[field].typ.rtype):
 			values[field] = genValueRecursiveInterface(c1, typ.field[field].typ.rtype)
+		case isInterfaceSrc(typ.field[field].typ) && len(typ.field[field].typ.field) > 0:
+			// Keep the concrete type, needed to call interface methods.
+			values[field] = genValueInterface(c1)
 		case isInterface(typ.field[field].typ):
 			values[field] = genInterfaceWrapper(c1, typ.field[field].typ.rtype)
 		default:
@@ -2156,7 +2860,8 @@ func doCompositeSparse(n *node, hasType bool) {
 		switch {
 		case d.Type().Kind() == reflect.Ptr:
 			d.Set(a.Addr())
-		case destInterface:
+		case destInterface && d.Type() == valueInterfaceType:
+			// The literal is stored directly in the interface destination.
 			d.Set(reflect.ValueOf(valueInterface{n, a}))
 		default:
 			d.Set(a)
@@ -2227,6 +2932,7 @@ func _range(n *node) {
 func rangeChan(n *node) {
 	i := n.child[0].findex        // element index location in frame
 	value := genValue(n.child[1]) // chan
+	elem := genChanElem(n.child[0])
 	fnext := getExec(n.fnext)
 	tnext := getExec(n.tnext)
 
@@ -2242,7 +2948,7 @@ func rangeChan(n *node) {
 		if !ok {
 			return fnext
 		}
-		f.data[i].Set(v)
+		f.data[i].Set(elem(v))
 		return tnext
 	}
 }
@@ -2259,7 +2965,7 @@ func rangeMap(n *node) {
 		value = genValue(n.child[2]) // map
 		if n.child[1].typ.cat == interfaceT {
 			n.exec = func(f *frame) bltn {
-				iter := f.data[index2].Interface().(*reflect.MapIter)
+				iter := f.data[index2].Interface().(mapIterator)
 				if !iter.Next() {
 					return fnext
 				}
@@ -2273,7 +2979,7 @@ func rangeMap(n *node) {
 			}
 		} else {
 			n.exec = func(f *frame) bltn {
-				iter := f.data[index2].Interface().(*reflect.MapIter)
+				iter := f.data[index2].Interface().(mapIterator)
 				if !iter.Next() {
 					return fnext
 				}
@@ -2285,7 +2991,7 @@ func rangeMap(n *node) {
 	} else {
 		value = genValue(n.child[1]) // map
 		n.exec = func(f *frame) bltn {
-			iter := f.data[index2].Interface().(*reflect.MapIter)
+			iter := f.data[index2].Interface().(mapIterator)
 			if !iter.Next() {
 				return fnext
 			}
@@ -2296,12 +3002,149 @@ func rangeMap(n *node) {
 
 	// Init sequence
 	next := n.exec
+	if n.interp.sortedMaps {
+		n.child[0].exec = func(f *frame) bltn {
+			f.data[index2].Set(reflect.ValueOf(newSortedMapIter(value(f))))
+			return next
+		}
+		return
+	}
 	n.child[0].exec = func(f *frame) bltn {
 		f.data[index2].Set(reflect.ValueOf(value(f).MapRange()))
 		return next
 	}
 }
 
+// mapIterator iterates over the entries of a map, as reflect.MapIter.
+type mapIterator interface {
+	Next() bool
+	Key() reflect.Value
+	Value() reflect.Value
+}
+
+// sortedMapIter iterates over the entries of a map in sorted key order.
+type sortedMapIter struct {
+	m    reflect.Value
+	keys []reflect.Value
+	i    int
+	val  reflect.Value
+}
+
+func newSortedMapIter(m reflect.Value) *sortedMapIter {
+	keys := m.MapKeys()
+	less := keyLess(m.Type().Key())
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return &sortedMapIter{m: m, keys: keys, i: -1}
+}
+
+// Next advances the iterator, skipping the entries deleted during iteration.
+func (it *sortedMapIter) Next() bool {
+	for it.i++; it.i < len(it.keys); it.i++ {
+		if it.val = it.m.MapIndex(it.keys[it.i]); it.val.IsValid() {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *sortedMapIter) Key() reflect.Value { return it.keys[it.i] }
+
+func (it *sortedMapIter) Value() reflect.Value { return it.val }
+
+// keyLess returns the ordering function of map keys of type t.
+func keyLess(t reflect.Type) func(a, b reflect.Value) bool {
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(a, b reflect.Value) bool { return !a.Bool() && b.Bool() }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+	case reflect.Float32, reflect.Float64:
+		return func(a, b reflect.Value) bool { return a.Float() < b.Float() }
+	case reflect.String:
+		return func(a, b reflect.Value) bool { return a.String() < b.String() }
+	}
+	panic(fmt.Sprintf("cannot range over map with keys of type %s in deterministic mode", t))
+}
+
+// typeSwitchMatch returns a function which checks if the dynamic type of the
+// interface value v matches typ, the type of a type switch clause, and returns
+// the value to assign to the clause variable.
+func typeSwitchMatch(n *node, typ *itype) func(*frame, reflect.Value) (reflect.Value, bool) {
+	if typ.cat == nilT {
+		return func(f *frame, v reflect.Value) (reflect.Value, bool) {
+			_, v = dynamicValue(v)
+			return reflect.Value{}, !v.IsValid()
+		}
+	}
+	rtype := typ.TypeOf()
+	isSrc := isInterfaceSrc(typ)
+	isBin := !isSrc && rtype.Kind() == reflect.Interface
+	id := typ.id()
+
+	return func(f *frame, v reflect.Value) (reflect.Value, bool) {
+		vi, v := dynamicValue(v)
+		if !v.IsValid() {
+			return v, false
+		}
+		if t := vi.node.typ; t.cat != valueT {
+			// Dynamic type is an interpreted type.
+			switch {
+			case t.id() == id:
+				return v, true
+			case isSrc:
+				return reflect.ValueOf(vi), t.implements(typ)
+			case isBin:
+				if t.methods().contains(typ.methods()) {
+					return wrapInterfaceValue(n, f, vi, rtype), true
+				}
+			}
+			return v, false
+		}
+		// Dynamic type is a binary type.
+		switch {
+		case isSrc:
+			return reflect.ValueOf(vi), vi.node.typ.implements(typ)
+		case isBin:
+			return v, v.Type().Implements(rtype)
+		}
+		return v, v.Type() == rtype
+	}
+}
+
+// dynamicValue returns the dynamic value v of an interface, and the
+// corresponding valueInterface. The returned value is invalid for a nil
+// interface.
+func dynamicValue(v reflect.Value) (valueInterface, reflect.Value) {
+	var vi valueInterface
+	for v.IsValid() {
+		if v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return vi, reflect.Value{}
+			}
+			v = v.Elem()
+			continue
+		}
+		if !v.CanInterface() {
+			break
+		}
+		w, ok := v.Interface().(valueInterface)
+		if !ok {
+			break
+		}
+		if w.node == nil || w.node.typ.cat == nilT {
+			return vi, reflect.Value{}
+		}
+		vi, v = w, w.value
+	}
+	if v.IsValid() && vi.node == nil {
+		// Binary value not yet in a valueInterface.
+		vi = valueInterface{&node{typ: &itype{cat: valueT, rtype: v.Type()}}, v}
+	}
+	return vi, v
+}
+
 func _case(n *node) {
 	tnext := getExec(n.tnext)
 
@@ -2326,49 +3169,29 @@ func _case(n *node) {
 				}
 			case 1:
 				// match against 1 type: assign var to concrete value
-				typ := types[0]
+				match := typeSwitchMatch(n, types[0])
 				n.exec = func(f *frame) bltn {
-					v := srcValue(f)
-					if !v.IsValid() {
-						// match zero value against nil
-						if typ.cat == nilT {
-							return tnext
-						}
+					v, ok := match(f, srcValue(f))
+					if !ok {
 						return fnext
 					}
-					if t := v.Type(); t.Kind() == reflect.Interface {
-						if typ.cat == nilT && v.IsNil() {
-							return tnext
-						}
-						if typ.TypeOf().String() == t.String() {
-							destValue(f).Set(v.Elem())
-							return tnext
-						}
-						return fnext
+					if v.IsValid() {
+						destValue(f).Set(v)
 					}
-					vi := v.Interface().(valueInterface)
-					if vi.node == nil {
-						if typ.cat == nilT {
-							return tnext
-						}
-						return fnext
-					}
-					if vi.node.typ.id() == typ.id() {
-						destValue(f).Set(vi.value)
-						return tnext
-					}
-					return fnext
+					return tnext
 				}
 			default:
 				// match against multiple types: assign var to interface value
+				matches := make([]func(*frame, reflect.Value) (reflect.Value, bool), len(types))
+				for i, typ := range types {
+					matches[i] = typeSwitchMatch(n, typ)
+				}
 				n.exec = func(f *frame) bltn {
 					val := srcValue(f)
-					if v := srcValue(f).Interface().(valueInterface).node; v != nil {
-						for _, typ := range types {
-							if v.typ.id() == typ.id() {
-								destValue(f).Set(val)
-								return tnext
-							}
+					for _, match := range matches {
+						if _, ok := match(f, val); ok {
+							destValue(f).Set(val)
+							return tnext
 						}
 					}
 					return fnext
@@ -2379,12 +3202,15 @@ func _case(n *node) {
 			if len(n.child) <= 1 {
 				n.exec = func(f *frame) bltn { return tnext }
 			} else {
+				matches := make([]func(*frame, reflect.Value) (reflect.Value, bool), len(types))
+				for i, typ := range types {
+					matches[i] = typeSwitchMatch(n, typ)
+				}
 				n.exec = func(f *frame) bltn {
-					if v := srcValue(f).Interface().(valueInterface).node; v != nil {
-						for _, typ := range types {
-							if v.typ.id() == typ.id() {
-								return tnext
-							}
+					val := srcValue(f)
+					for _, match := range matches {
+						if _, ok := match(f, val); ok {
+							return tnext
 						}
 					}
 					return fnext
@@ -2440,15 +3266,24 @@ func appendSlice(n *node) {
 }
 
 func _append(n *node) {
-	if c1, c2 := n.child[1], n.child[2]; len(n.child) == 3 && c2.typ.cat == arrayT && c2.typ.val.id() == n.typ.val.id() ||
-		isByteArray(c1.typ.TypeOf()) && isString(c2.typ.TypeOf()) {
-		appendSlice(n)
-		return
-	}
 	dest := genValueOutput(n, n.typ.rtype)
 	value := genValue(n.child[1])
 	next := getExec(n.tnext)
 
+	if len(n.child) == 2 {
+		// append(s) is a no-op returning s.
+		n.exec = func(f *frame) bltn {
+			dest(f).Set(value(f))
+			return next
+		}
+		return
+	}
+	if n.action == aCallSlice {
+		// Spread form: append(s, t...), or append(b, "string"...) for []byte.
+		appendSlice(n)
+		return
+	}
+
 	if len(n.child) > 3 {
 		args := n.child[2:]
 		l := len(args)
@@ -2701,6 +3536,7 @@ func reset(n *node) {
 // recv reads from a channel.
 func recv(n *node) {
 	value := genValue(n.child[0])
+	elem := genChanElem(n)
 	tnext := getExec(n.tnext)
 	i := n.findex
 	l := n.level
@@ -2738,7 +3574,7 @@ func recv(n *node) {
 				// Fast: channel read doesn't block
 				ch := value(f)
 				if r, ok := ch.TryRecv(); ok {
-					getFrame(f, l).data[i] = r
+					getFrame(f, l).data[i] = elem(r)
 					return tnext
 				}
 				// Slow: channel is blocked, allow cancel
@@ -2746,11 +3582,11 @@ func recv(n *node) {
 				done := f.done
 				f.mutex.RUnlock()
 
-				var chosen int
-				chosen, getFrame(f, l).data[i], _ = reflect.Select([]reflect.SelectCase{done, {Dir: reflect.SelectRecv, Chan: ch}})
+				chosen, v, _ := reflect.Select([]reflect.SelectCase{done, {Dir: reflect.SelectRecv, Chan: ch}})
 				if chosen == 0 {
 					return nil
 				}
+				getFrame(f, l).data[i] = elem(v)
 				return tnext
 			}
 		}
@@ -2768,7 +3604,8 @@ func recv(n *node) {
 		} else {
 			i := n.findex
 			n.exec = func(f *frame) bltn {
-				getFrame(f, l).data[i], _ = value(f).Recv()
+				v, _ := value(f).Recv()
+				getFrame(f, l).data[i] = elem(v)
 				return tnext
 			}
 		}
@@ -2779,6 +3616,7 @@ func recv2(n *node) {
 	vchan := genValue(n.child[0])    // chan
 	vres := genValue(n.anc.child[0]) // result
 	vok := genValue(n.anc.child[1])  // status
+	elem := genChanElem(n.anc.child[0])
 	tnext := getExec(n.tnext)
 
 	if n.interp.cancelChan {
@@ -2787,7 +3625,7 @@ func recv2(n *node) {
 			ch, result, status := vchan(f), vres(f), vok(f)
 			//  Fast: channel read doesn't block
 			if v, ok := ch.TryRecv(); ok {
-				result.Set(v)
+				result.Set(elem(v))
 				status.SetBool(true)
 				return tnext
 			}
@@ -2800,7 +3638,7 @@ func recv2(n *node) {
 			if chosen == 0 {
 				return nil
 			}
-			result.Set(v)
+			result.Set(elem(v))
 			status.SetBool(ok)
 			return tnext
 		}
@@ -2808,7 +3646,7 @@ func recv2(n *node) {
 		// Blocking channel read (less overhead)
 		n.exec = func(f *frame) bltn {
 			v, ok := vchan(f).Recv()
-			vres(f).Set(v)
+			vres(f).Set(elem(v))
 			vok(f).SetBool(ok)
 			return tnext
 		}
@@ -2844,6 +3682,14 @@ func convertConstantValue(n *node) {
 	v := n.rval
 	typ := n.typ.TypeOf()
 	kind := typ.Kind()
+	// An untyped constant may have another numeric kind than its type,
+	// as an integer value from a complex constant expression.
+	switch {
+	case isInt(typ) || isUint(typ):
+		c = constant.ToInt(c)
+	case isFloat(typ):
+		c = constant.ToFloat(c)
+	}
 	switch kind {
 	case reflect.Bool:
 		v = reflect.ValueOf(constant.BoolVal(c)).Convert(typ)
@@ -2937,12 +3783,18 @@ func send(n *node) {
 	}
 }
 
+// clauseChanDir returns the channel, the assigned and status nodes, and the
+// direction of the channel operation of the comm clause n.
 func clauseChanDir(n *node) (*node, *node, *node, reflect.SelectDir) {
 	dir := reflect.SelectDefault
 	var nod, assigned, ok *node
 	var stop bool
 
-	n.Walk(func(m *node) bool {
+	// Only the comm statement is searched, not the clause body.
+	n.child[0].Walk(func(m *node) bool {
+		if stop {
+			return false
+		}
 		switch m.action {
 		case aRecv:
 			dir = reflect.SelectRecv
@@ -2975,51 +3827,65 @@ func _select(n *node) {
 	chanValues := make([]func(*frame) reflect.Value, nbClause)
 	assignedValues := make([]func(*frame) reflect.Value, nbClause)
 	okValues := make([]func(*frame) reflect.Value, nbClause)
-	cases := make([]reflect.SelectCase, nbClause+1)
+	elems := make([]func(reflect.Value) reflect.Value, nbClause)
+	dirs := make([]reflect.SelectDir, nbClause)
 	next := getExec(n.tnext)
 
 	for i := 0; i < nbClause; i++ {
 		if len(n.child[i].child) == 0 {
 			// The comm clause is an empty default, exit select.
-			cases[i].Dir = reflect.SelectDefault
+			dirs[i] = reflect.SelectDefault
 			clause[i] = func(*frame) bltn { return next }
 		} else {
 			switch c0 := n.child[i].child[0]; {
-			case len(n.child[i].child) > 1:
+			case n.child[i].kind == commClauseDefault:
+				// The comm clause has a default clause.
+				clause[i] = getExec(c0.start)
+				dirs[i] = reflect.SelectDefault
+			case len(n.child[i].child) > 1 || c0.kind != exprStmt && c0.kind != sendStmt:
 				// The comm clause contains a channel operation and a clause body.
-				clause[i] = getExec(n.child[i].child[1].start)
-				chans[i], assigned[i], ok[i], cases[i].Dir = clauseChanDir(n.child[i])
+				if len(n.child[i].child) > 1 {
+					clause[i] = getExec(n.child[i].child[1].start)
+				} else {
+					clause[i] = func(*frame) bltn { return next }
+				}
+				chans[i], assigned[i], ok[i], dirs[i] = clauseChanDir(n.child[i])
 				chanValues[i] = genValue(chans[i])
 				if assigned[i] != nil {
 					assignedValues[i] = genValue(assigned[i])
+					elems[i] = genChanElem(assigned[i])
 				}
 				if ok[i] != nil {
 					okValues[i] = genValue(ok[i])
 				}
 			case c0.kind == exprStmt && len(c0.child) == 1 && c0.child[0].action == aRecv:
 				// The comm clause has an empty body clause after channel receive.
+				clause[i] = func(*frame) bltn { return next }
 				chanValues[i] = genValue(c0.child[0].child[0])
-				cases[i].Dir = reflect.SelectRecv
+				dirs[i] = reflect.SelectRecv
 			case c0.kind == sendStmt:
 				// The comm clause as an empty body clause after channel send.
+				clause[i] = func(*frame) bltn { return next }
 				chanValues[i] = genValue(c0.child[0])
-				cases[i].Dir = reflect.SelectSend
+				dirs[i] = reflect.SelectSend
 				assignedValues[i] = genValue(c0.child[1])
-			default:
-				// The comm clause has a default clause.
-				clause[i] = getExec(c0.start)
-				cases[i].Dir = reflect.SelectDefault
 			}
 		}
 	}
 
 	n.exec = func(f *frame) bltn {
+		// The cases are allocated at each execution, as the same select
+		// may run concurrently in several goroutines. The last case is the
+		// cancellation channel of the frame, to unblock the select when the
+		// execution is stopped.
+		cases := make([]reflect.SelectCase, nbClause+1)
 		f.mutex.RLock()
 		cases[nbClause] = f.done
 		f.mutex.RUnlock()
 
-		for i := range cases[:nbClause] {
-			switch cases[i].Dir {
+		for i, dir := range dirs {
+			cases[i].Dir = dir
+			switch dir {
 			case reflect.SelectRecv:
 				cases[i].Chan = chanValues[i](f)
 			case reflect.SelectSend:
@@ -3034,7 +3900,7 @@ func _select(n *node) {
 			return nil
 		}
 		if cases[j].Dir == reflect.SelectRecv && assignedValues[j] != nil {
-			assignedValues[j](f).Set(v)
+			assignedValues[j](f).Set(elems[j](v))
 			if ok[j] != nil {
 				okValues[j](f).SetBool(s)
 			}
@@ -3209,22 +4075,41 @@ func isNotNil(n *node) {
 }
 
 func complexConst(n *node) {
-	if v0, v1 := n.child[1].rval, n.child[2].rval; v0.IsValid() && v1.IsValid() {
-		n.rval = reflect.ValueOf(complex(vFloat(v0), vFloat(v1)))
-		n.gen = nop
+	v0, v1 := n.child[1].rval, n.child[2].rval
+	if !v0.IsValid() || !v1.IsValid() {
+		return
 	}
+	if c0, c1 := vConstantValue(v0), vConstantValue(v1); c0 != nil && c1 != nil {
+		// Untyped constant: the result is untyped too.
+		n.rval = reflect.ValueOf(constant.BinaryOp(constant.ToFloat(c0), token.ADD, constant.MakeImag(constant.ToFloat(c1))))
+	} else {
+		n.rval = reflect.New(n.typ.rtype).Elem()
+		n.rval.SetComplex(complex(vFloat(v0), vFloat(v1)))
+	}
+	n.gen = nop
 }
 
 func imagConst(n *node) {
 	if v := n.child[1].rval; v.IsValid() {
-		n.rval = reflect.ValueOf(imag(v.Complex()))
+		n.rval = complexPartConst(v, n.typ, constant.Imag, func(c complex128) float64 { return imag(c) })
 		n.gen = nop
 	}
 }
 
 func realConst(n *node) {
 	if v := n.child[1].rval; v.IsValid() {
-		n.rval = reflect.ValueOf(real(v.Complex()))
+		n.rval = complexPartConst(v, n.typ, constant.Real, func(c complex128) float64 { return real(c) })
 		n.gen = nop
 	}
 }
+
+// complexPartConst returns the real or imaginary part of the constant v,
+// as an untyped constant if v is untyped, or as a value of type t otherwise.
+func complexPartConst(v reflect.Value, t *itype, part func(constant.Value) constant.Value, fn func(complex128) float64) reflect.Value {
+	if c := vConstantValue(v); c != nil {
+		return reflect.ValueOf(part(constant.ToComplex(c)))
+	}
+	r := reflect.New(t.rtype).Elem()
+	r.SetFloat(fn(v.Complex()))
+	return r
+}
@@ -0,0 +1,228 @@
+package interp
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TestResult is the result of a test function run by RunTests.
+type TestResult struct {
+	Name    string        // name of the test, or parent/name for a subtest
+	Failed  bool          // the test failed
+	Skipped bool          // the test was skipped
+	Output  []string      // messages logged by the test, in order
+	Elapsed time.Duration // duration of the test
+}
+
+// RunTests evaluates src, then runs its test functions, of the form
+// func TestXxx(t *testing.T), in source order, and returns their results.
+// The results of subtests started by t.Run follow the one of their parent.
+//
+// In the interpreter, the testing.T type is replaced by a host type which
+// records the test messages and failures. It implements the methods of
+// testing.T used in tests, but can not be passed to binary code expecting
+// a *testing.T or a testing.TB. The tests run sequentially, t.Parallel has no
+// effect.
+func (interp *Interpreter) RunTests(src string) ([]TestResult, error) {
+	interp.Use(Exports{"testing": {"T": reflect.ValueOf((*testingT)(nil))}})
+
+	if _, err := interp.Eval(src); err != nil {
+		return nil, err
+	}
+
+	pkgName := mainID
+	fset := token.NewFileSet()
+	if firstToken(fset, src) == token.PACKAGE {
+		f, err := parser.ParseFile(fset, "", src, parser.PackageClauseOnly)
+		if err != nil {
+			return nil, err
+		}
+		pkgName = f.Name.Name
+	}
+
+	interp.mutex.RLock()
+	var tests []*symbol
+	var names []string
+	if sc := interp.scopes[pkgName]; sc != nil {
+		for name, sym := range sc.sym {
+			if sym.kind == funcSym && sym.node != nil && sym.node.kind == funcDecl && isTestName(name) {
+				tests = append(tests, sym)
+				names = append(names, name)
+			}
+		}
+	}
+	interp.mutex.RUnlock()
+	sort.Sort(bySymbolPos{tests, names})
+
+	r := &testRunner{}
+	for _, name := range names {
+		qname := name
+		if pkgName != mainID {
+			qname = pkgName + "." + name
+		}
+		fn, err := interp.EvalFunc(qname, func(*testingT) {})
+		if err != nil {
+			return nil, fmt.Errorf("wrong signature for %s, must be: func %s(t *testing.T)", name, name)
+		}
+		r.run(name, fn.Interface().(func(*testingT)))
+	}
+	return r.results, nil
+}
+
+// isTestName returns true if name is the name of a test function: "Test",
+// followed by a name not starting with a lower case letter, as in go test.
+func isTestName(name string) bool {
+	if !strings.HasPrefix(name, "Test") {
+		return false
+	}
+	if len(name) == len("Test") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len("Test"):])
+	return !unicode.IsLower(r)
+}
+
+// bySymbolPos sorts test symbols and their names by position in source.
+type bySymbolPos struct {
+	syms  []*symbol
+	names []string
+}
+
+func (s bySymbolPos) Len() int           { return len(s.syms) }
+func (s bySymbolPos) Less(i, j int) bool { return s.syms[i].node.pos < s.syms[j].node.pos }
+func (s bySymbolPos) Swap(i, j int) {
+	s.syms[i], s.syms[j] = s.syms[j], s.syms[i]
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+}
+
+// testRunner collects the results of tests.
+type testRunner struct {
+	mu      sync.Mutex
+	results []TestResult
+}
+
+// run runs the test fn in its own goroutine, as FailNow and SkipNow
+// terminate it, and returns true if the test failed.
+func (r *testRunner) run(name string, fn func(*testingT)) bool {
+	r.mu.Lock()
+	index := len(r.results)
+	r.results = append(r.results, TestResult{Name: name})
+	r.mu.Unlock()
+
+	t := &testingT{name: name, runner: r}
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer t.runCleanups()
+		defer func() {
+			if v := recover(); v != nil {
+				t.Error("panic:", v)
+			}
+		}()
+		fn(t)
+	}()
+	<-done
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r.mu.Lock()
+	r.results[index] = TestResult{Name: name, Failed: t.failed, Skipped: t.skipped, Output: t.output, Elapsed: time.Since(start)}
+	r.mu.Unlock()
+	return t.failed
+}
+
+// testingT replaces testing.T in the interpreter, to record test results.
+type testingT struct {
+	name   string
+	runner *testRunner
+
+	mu       sync.Mutex
+	failed   bool
+	skipped  bool
+	output   []string
+	cleanups []func()
+}
+
+func (t *testingT) log(s string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.output = append(t.output, strings.TrimSuffix(s, "\n"))
+}
+
+func (t *testingT) runCleanups() {
+	t.mu.Lock()
+	cleanups := t.cleanups
+	t.cleanups = nil
+	t.mu.Unlock()
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+// The following methods are the ones of testing.T.
+
+func (t *testingT) Name() string                              { return t.name }
+func (t *testingT) Log(args ...interface{})                   { t.log(fmt.Sprintln(args...)) }
+func (t *testingT) Logf(format string, args ...interface{})   { t.log(fmt.Sprintf(format, args...)) }
+func (t *testingT) Error(args ...interface{})                 { t.Log(args...); t.Fail() }
+func (t *testingT) Errorf(format string, args ...interface{}) { t.Logf(format, args...); t.Fail() }
+func (t *testingT) Fatal(args ...interface{})                 { t.Log(args...); t.FailNow() }
+func (t *testingT) Fatalf(format string, args ...interface{}) { t.Logf(format, args...); t.FailNow() }
+func (t *testingT) Skip(args ...interface{})                  { t.Log(args...); t.SkipNow() }
+func (t *testingT) Skipf(format string, args ...interface{})  { t.Logf(format, args...); t.SkipNow() }
+func (t *testingT) Helper()                                   {}
+func (t *testingT) Parallel()                                 {}
+
+func (t *testingT) Fail() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failed = true
+}
+
+func (t *testingT) Failed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failed
+}
+
+func (t *testingT) FailNow() {
+	t.Fail()
+	runtime.Goexit()
+}
+
+func (t *testingT) SkipNow() {
+	t.mu.Lock()
+	t.skipped = true
+	t.mu.Unlock()
+	runtime.Goexit()
+}
+
+func (t *testingT) Skipped() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.skipped
+}
+
+func (t *testingT) Cleanup(f func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *testingT) Run(name string, f func(t *testingT)) bool {
+	if t.runner.run(t.name+"/"+name, f) {
+		t.Fail()
+		return false
+	}
+	return true
+}
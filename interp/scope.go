@@ -105,6 +105,15 @@ func (s *scope) push(indirect bool) *scope {
 func (s *scope) pushBloc() *scope { return s.push(false) }
 func (s *scope) pushFunc() *scope { return s.push(true) }
 
+// funcScope returns the top scope of the function the current scope belongs to,
+// where labels are defined.
+func (s *scope) funcScope() *scope {
+	for s.anc != nil && s.anc.level == s.level {
+		s = s.anc
+	}
+	return s
+}
+
 func (s *scope) pop() *scope {
 	if s.level == s.anc.level {
 		// propagate size and types, as scopes at same level share the same frame
@@ -137,7 +146,7 @@ func (s *scope) rangeChanType(n *node) *itype {
 		}
 	}
 
-	c := n.child[1]
+	c := n.child[len(n.child)-2] // range expression
 	if c.typ == nil {
 		return nil
 	}
@@ -2,9 +2,13 @@ package interp
 
 import (
 	"fmt"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -27,7 +31,7 @@ func (interp *Interpreter) importSrc(rPath, importPath string) (string, error) {
 	// base path is the directory of the interpreter input file, or "." if no file
 	// was provided.
 	// In all other cases, absolute import paths are resolved from the GOPATH
-	// and the nested "vendor" directories.
+	// entries, in order, and the nested "vendor" directories.
 	if isPathRelative(importPath) {
 		if rPath == mainID {
 			rPath = "."
@@ -43,7 +47,7 @@ func (interp *Interpreter) importSrc(rPath, importPath string) (string, error) {
 		} else {
 			root = rPath
 		}
-		if dir, rPath, err = pkgDir(interp.context.GOPATH, root, importPath); err != nil {
+		if dir, rPath, err = goPathPkgDir(interp.context.GOPATH, root, importPath); err != nil {
 			return "", err
 		}
 	}
@@ -52,6 +56,8 @@ func (interp *Interpreter) importSrc(rPath, importPath string) (string, error) {
 		return "", fmt.Errorf("import cycle not allowed\n\timports %s", importPath)
 	}
 	interp.rdir[importPath] = true
+	from := len(interp.instances)
+	defer interp.dropInstances(from)
 
 	files, err := ioutil.ReadDir(dir)
 	if err != nil {
@@ -124,6 +130,9 @@ func (interp *Interpreter) importSrc(rPath, importPath string) (string, error) {
 		}
 		initNodes = append(initNodes, nodes...)
 	}
+	if err = interp.cfgInstances(from); err != nil {
+		return "", err
+	}
 
 	// Register source package in the interpreter. The package contains only
 	// the global symbols in the package scope.
@@ -163,12 +172,122 @@ func (interp *Interpreter) importSrc(rPath, importPath string) (string, error) {
 	return pkgName, nil
 }
 
+// Imports returns the sorted list of the packages imported by src, directly
+// or through the imported source packages, without evaluating anything.
+// It can be used to check the dependencies of a script before running it.
+func (interp *Interpreter) Imports(src string) ([]string, error) {
+	fset := token.NewFileSet()
+	if firstToken(fset, src) != token.PACKAGE {
+		// Allow incremental scripts, as in Eval.
+		src = "package main;" + src
+	}
+	paths, err := importPaths(fset, interp.name, src)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, p := range paths {
+		if err := interp.srcImports(fset, seen, mainID, p); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]string, 0, len(seen))
+	for p := range seen {
+		res = append(res, p)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// srcImports records importPath in seen and, for a source package not yet
+// visited, the packages imported by its files. rPath is as in importSrc.
+func (interp *Interpreter) srcImports(fset *token.FileSet, seen map[string]bool, rPath, importPath string) error {
+	if seen[importPath] {
+		return nil
+	}
+	seen[importPath] = true
+	if interp.binPkg[importPath] != nil {
+		return nil
+	}
+
+	var dir string
+	var err error
+	if isPathRelative(importPath) {
+		if rPath == mainID {
+			rPath = "."
+		}
+		dir = filepath.Join(filepath.Dir(interp.name), rPath, importPath)
+	} else {
+		root := rPath
+		if rPath == mainID {
+			if root, err = interp.rootFromSourceLocation(); err != nil {
+				return err
+			}
+		}
+		if dir, rPath, err = goPathPkgDir(interp.context.GOPATH, root, importPath); err != nil {
+			return err
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	subRPath := effectivePkg(rPath, importPath)
+	for _, file := range files {
+		name := filepath.Join(dir, file.Name())
+		if skipFile(&interp.context, name) {
+			continue
+		}
+		buf, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		ok, err := interp.MatchFile(name, buf)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		paths, err := importPaths(fset, name, string(buf))
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			if err := interp.srcImports(fset, seen, subRPath, p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importPaths returns the import paths declared in a Go source file.
+func importPaths(fset *token.FileSet, name, src string) ([]string, error) {
+	f, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(f.Imports))
+	for _, spec := range f.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
 // rootFromSourceLocation returns the path to the directory containing the input
 // Go file given to the interpreter, relative to $GOPATH/src.
 // It is meant to be called in the case when the initial input is a main package.
 func (interp *Interpreter) rootFromSourceLocation() (string, error) {
 	sourceFile := interp.name
-	if sourceFile == DefaultSourceName {
+	if sourceFile == "" || sourceFile == DefaultSourceName {
 		return "", nil
 	}
 	wd, err := os.Getwd()
@@ -176,13 +295,38 @@ func (interp *Interpreter) rootFromSourceLocation() (string, error) {
 		return "", err
 	}
 	pkgDir := filepath.Join(wd, filepath.Dir(sourceFile))
-	root := strings.TrimPrefix(pkgDir, filepath.Join(interp.context.GOPATH, "src")+"/")
+	root := pkgDir
+	for _, goPath := range filepath.SplitList(interp.context.GOPATH) {
+		if root = strings.TrimPrefix(pkgDir, filepath.Join(goPath, "src")+"/"); root != pkgDir {
+			break
+		}
+	}
 	if root == wd {
 		return "", fmt.Errorf("package location %s not in GOPATH", pkgDir)
 	}
 	return root, nil
 }
 
+// goPathPkgDir returns the result of pkgDir for the first entry of the
+// list of directories goPath where the package is found.
+func goPathPkgDir(goPath string, root, importPath string) (string, string, error) {
+	list := filepath.SplitList(goPath)
+	if len(list) == 0 {
+		list = []string{goPath}
+	}
+	var err error
+	for i, p := range list {
+		dir, rPath, e := pkgDir(p, root, importPath)
+		if e == nil {
+			return dir, rPath, nil
+		}
+		if i == 0 {
+			err = e
+		}
+	}
+	return "", "", err
+}
+
 // pkgDir returns the absolute path in filesystem for a package given its import path
 // and the root of the subtree dependencies.
 func pkgDir(goPath string, root, importPath string) (string, string, error) {
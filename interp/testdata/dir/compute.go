@@ -0,0 +1,5 @@
+package main
+
+const base = 21
+
+func compute() int { return base * 2 }
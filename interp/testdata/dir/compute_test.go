@@ -0,0 +1,5 @@
+package main
+
+import "fmt"
+
+func TestCompute() string { return fmt.Sprint("compute ", compute()) }
@@ -0,0 +1,9 @@
+package main
+
+import "fmt"
+
+var total = compute()
+
+func main() {
+	fmt.Println(total, name)
+}
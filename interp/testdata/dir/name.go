@@ -0,0 +1,5 @@
+// +build !yaegi_skip
+
+package main
+
+const name = "dir"
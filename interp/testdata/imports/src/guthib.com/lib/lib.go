@@ -0,0 +1,9 @@
+package lib
+
+import (
+	"strings"
+
+	"guthib.com/util"
+)
+
+func Upper(s string) string { return util.Trim(strings.ToUpper(s)) }
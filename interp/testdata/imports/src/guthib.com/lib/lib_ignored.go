@@ -0,0 +1,7 @@
+// +build ignore
+
+package lib
+
+import "os"
+
+var _ = os.Args
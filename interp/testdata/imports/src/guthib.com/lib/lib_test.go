@@ -0,0 +1,5 @@
+package lib
+
+import "testing"
+
+func TestUpper(t *testing.T) {}
@@ -0,0 +1,5 @@
+package util
+
+import "strings"
+
+func Trim(s string) string { return strings.TrimSpace(s) }
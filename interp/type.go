@@ -23,12 +23,14 @@ const (
 	chanT
 	chanSendT
 	chanRecvT
+	comparableT
 	complex64T
 	complex128T
 	errorT
 	float32T
 	float64T
 	funcT
+	genericT
 	interfaceT
 	intT
 	int8T
@@ -60,12 +62,14 @@ var cats = [...]string{
 	boolT:       "boolT",
 	builtinT:    "builtinT",
 	chanT:       "chanT",
+	comparableT: "comparableT",
 	complex64T:  "complex64T",
 	complex128T: "complex128T",
 	errorT:      "errorT",
 	float32T:    "float32",
 	float64T:    "float64T",
 	funcT:       "funcT",
+	genericT:    "genericT",
 	interfaceT:  "interfaceT",
 	intT:        "intT",
 	int8T:       "int8T",
@@ -123,6 +127,8 @@ type itype struct {
 	isBinMethod bool          // true if the type refers to a bin method function
 	node        *node         // root AST node of type definition
 	scope       *scope        // type declaration scope (in case of re-parse incomplete type)
+	terms       []typeTerm    // type set of a constraint interface, or nil for all types
+	constraint  *itype        // constraint of a type parameter, for the check of a generic declaration
 }
 
 func untypedBool() *itype    { return &itype{cat: boolT, name: "bool", untyped: true} }
@@ -260,6 +266,10 @@ func nodeType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
 				t = t1
 			}
 		}
+		if !t.isComplete() {
+			// The operand type is not known yet, the caller must come back later.
+			break
+		}
 		// If the node is to be assigned or returned, the node type is the destination type.
 		dt := t
 		switch a := n.anc; {
@@ -315,12 +325,12 @@ func nodeType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
 				}
 				if !t.incomplete {
 					switch k := t.TypeOf().Kind(); {
+					case t.untyped && isNumber(t.TypeOf()):
+						t = untypedFloat()
 					case k == reflect.Complex64:
 						t = sc.getType("float32")
 					case k == reflect.Complex128:
 						t = sc.getType("float64")
-					case t.untyped && isNumber(t.TypeOf()):
-						t = &itype{cat: valueT, rtype: floatType, untyped: true, scope: sc}
 					default:
 						err = n.cfgErrorf("invalid complex type %s", k)
 					}
@@ -342,6 +352,18 @@ func nodeType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
 			if t, err = nodeType(interp, sc, n.child[0]); err != nil {
 				return nil, err
 			}
+			if t.cat == genericT {
+				var sym *symbol
+				if sym, err = interp.callInstance(sc, n); err != nil {
+					return nil, err
+				}
+				if sym == nil {
+					// Argument types are not known yet.
+					t = &itype{node: n, scope: sc, incomplete: true}
+					break
+				}
+				t = sym.typ
+			}
 			switch t.cat {
 			case valueT:
 				if rt := t.rtype; rt.Kind() == reflect.Func && rt.NumOut() == 1 {
@@ -446,6 +468,9 @@ func nodeType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
 		if t.node == nil {
 			t.node = n
 		}
+		if err = genericUse(n, t); err == nil {
+			err = constraintUse(n, t)
+		}
 
 	case indexExpr:
 		var lt *itype
@@ -456,6 +481,28 @@ func nodeType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
 			t.incomplete = true
 			break
 		}
+		if lt.cat == genericT {
+			if lt.node != nil && lt.node.kind == funcDecl && isCall(n.anc) && n.anc.child[0] == n {
+				// The instantiation is completed by inference from the call arguments.
+				t = lt
+				break
+			}
+			var types []*itype
+			var incomplete bool
+			if types, incomplete, err = typeArgs(interp, sc, n.child[1:]); err != nil {
+				return nil, err
+			}
+			if incomplete || lt.node == nil {
+				t.incomplete = true
+				break
+			}
+			var sym *symbol
+			if sym, err = interp.instantiate(lt, types, n); err != nil {
+				return nil, err
+			}
+			t = sym.typ
+			break
+		}
 		switch lt.cat {
 		case arrayT, mapT:
 			t = lt.val
@@ -469,13 +516,44 @@ func nodeType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
 				sym.typ = t
 			}
 		}
+		var fieldNodes []*node // declaring node of each field, to report duplicate methods
 		for _, field := range n.child[0].child {
 			if len(field.child) == 1 {
+				if isTypeTerms(field.child[0]) {
+					// Union or approximation element of a constraint type set.
+					terms, err := typeTerms(interp, sc, field.child[0])
+					if err != nil {
+						return nil, err
+					}
+					for _, term := range terms {
+						incomplete = incomplete || term.typ.incomplete
+					}
+					t.terms = intersectTerms(t.terms, terms)
+					continue
+				}
 				typ, err := nodeType(interp, sc, field.child[0])
 				if err != nil {
 					return nil, err
 				}
+				if typ.cat == valueT && typ.rtype.Kind() == reflect.Interface {
+					// Flatten the method set of an embedded binary interface.
+					for i := 0; i < typ.rtype.NumMethod(); i++ {
+						m := typ.rtype.Method(i)
+						t.field = append(t.field, structField{name: m.Name, typ: binMethodType(m.Type)})
+						fieldNodes = append(fieldNodes, field)
+					}
+					continue
+				}
+				if !typ.incomplete && typ.cat != comparableT && !isInterface(typ) {
+					// A single type element restricts the type set to this type.
+					t.terms = intersectTerms(t.terms, []typeTerm{{typ: typ}})
+					continue
+				}
+				if typ.terms != nil {
+					t.terms = intersectTerms(t.terms, typ.terms)
+				}
 				t.field = append(t.field, structField{name: fieldName(field.child[0]), embed: true, typ: typ})
+				fieldNodes = append(fieldNodes, field)
 				incomplete = incomplete || typ.incomplete
 			} else {
 				typ, err := nodeType(interp, sc, field.child[1])
@@ -483,10 +561,16 @@ func nodeType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
 					return nil, err
 				}
 				t.field = append(t.field, structField{name: field.child[0].ident, typ: typ})
+				fieldNodes = append(fieldNodes, field)
 				incomplete = incomplete || typ.incomplete
 			}
 		}
 		t.incomplete = incomplete
+		if !incomplete {
+			if err := checkInterfaceMethods(t, fieldNodes); err != nil {
+				return nil, err
+			}
+		}
 
 	case landExpr, lorExpr:
 		t.cat = boolT
@@ -540,6 +624,10 @@ func nodeType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
 		switch lt.cat {
 		case binPkgT:
 			pkg := interp.binPkg[lt.path]
+			if !interp.isAllowed(lt.path) {
+				err = n.cfgErrorf("use of package %q denied: package not allowed", lt.path)
+				panic(err)
+			}
 			if v, ok := pkg[name]; ok {
 				t.cat = valueT
 				t.rtype = v.Type()
@@ -602,7 +690,7 @@ func nodeType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
 				t.field = append(t.field, structField{name: fieldName(c.child[0]), embed: true, typ: typ})
 				incomplete = incomplete || typ.incomplete
 			case len(c.child) == 2 && c.child[1].kind == basicLit:
-				tag := c.child[1].rval.String()
+				tag := vString(c.child[1].rval)
 				typ, err := nodeType(interp, sc, c.child[0])
 				if err != nil {
 					return nil, err
@@ -613,7 +701,7 @@ func nodeType(interp *Interpreter, sc *scope, n *node) (*itype, error) {
 				var tag string
 				l := len(c.child)
 				if c.lastChild().kind == basicLit {
-					tag = c.lastChild().rval.String()
+					tag = vString(c.lastChild().rval)
 					l--
 				}
 				typ, err := nodeType(interp, sc, c.child[l-1])
@@ -913,6 +1001,17 @@ func (t *itype) assignableTo(o *itype) bool {
 	if t.isNil() && o.hasNil() || o.isNil() && t.hasNil() {
 		return true
 	}
+	if o.cat == interfaceT && !isInterface(t) && t.cat != ptrT {
+		// A value does not implement an interface through pointer receiver methods.
+		ims := o.methods()
+		if !t.methods().contains(ims) && (&itype{cat: ptrT, val: t}).methods().contains(ims) {
+			return false
+		}
+	}
+	if isInterfaceSrc(t) && o.cat == valueT && o.rtype.Kind() == reflect.Interface {
+		// An interpreted interface is assignable to a binary interface with a subset of its methods.
+		return t.methods().contains(o.methods())
+	}
 	return t.TypeOf().AssignableTo(o.TypeOf())
 }
 
@@ -957,6 +1056,28 @@ func (t *itype) equals(o *itype) bool {
 // MethodSet defines the set of methods signatures as strings, indexed per method name.
 type methodSet map[string]string
 
+// checkInterfaceMethods returns an error if the interface type t has several
+// methods of the same name with different signatures, including the methods of
+// its embedded interfaces. The fields of t are declared by nodes.
+func checkInterfaceMethods(t *itype, nodes []*node) error {
+	sigs := methodSet{}
+	for i, f := range t.field {
+		var ms methodSet
+		if f.typ.cat == funcT {
+			ms = methodSet{f.name: f.typ.TypeOf().String()}
+		} else {
+			ms = f.typ.methods()
+		}
+		for name, sig := range ms {
+			if s, ok := sigs[name]; ok && s != sig {
+				return nodes[i].cfgErrorf("duplicate method %s", name)
+			}
+			sigs[name] = sig
+		}
+	}
+	return nil
+}
+
 // Contains returns true if the method set m contains the method set n.
 func (m methodSet) contains(n methodSet) bool {
 	for k, v := range n {
@@ -973,11 +1094,13 @@ func (m methodSet) equals(n methodSet) bool {
 }
 
 // Methods returns a map of method type strings, indexed by method names.
+// Methods with a pointer receiver are only part of the method set of the
+// pointer type, as in Go.
 func (t *itype) methods() methodSet {
 	seen := map[*itype]bool{}
-	var getMethods func(typ *itype) methodSet
+	var getMethods func(typ *itype, ptr bool) methodSet
 
-	getMethods = func(typ *itype) methodSet {
+	getMethods = func(typ *itype, ptr bool) methodSet {
 		res := make(methodSet)
 
 		if seen[typ] {
@@ -993,16 +1116,17 @@ func (t *itype) methods() methodSet {
 				if f.typ.cat == funcT {
 					res[f.name] = f.typ.TypeOf().String()
 				} else {
-					for k, v := range getMethods(f.typ) {
+					for k, v := range getMethods(f.typ, false) {
 						res[k] = v
 					}
 				}
 			}
 		case valueT, errorT:
 			// Get method from corresponding reflect.Type.
+			typ.TypeOf() // Ensure the rtype exists.
 			for i := typ.rtype.NumMethod() - 1; i >= 0; i-- {
 				m := typ.rtype.Method(i)
-				res[m.Name] = m.Type.String()
+				res[m.Name] = methodSignature(typ.rtype, m)
 			}
 		case ptrT:
 			if typ.val.cat == valueT {
@@ -1010,10 +1134,10 @@ func (t *itype) methods() methodSet {
 				typ.TypeOf() // Ensure the rtype exists.
 				for i := typ.rtype.NumMethod() - 1; i >= 0; i-- {
 					m := typ.rtype.Method(i)
-					res[m.Name] = m.Type.String()
+					res[m.Name] = methodSignature(typ.rtype, m)
 				}
 			}
-			for k, v := range getMethods(typ.val) {
+			for k, v := range getMethods(typ.val, true) {
 				res[k] = v
 			}
 		case structT:
@@ -1021,19 +1145,56 @@ func (t *itype) methods() methodSet {
 				if !f.embed {
 					continue
 				}
-				for k, v := range getMethods(f.typ) {
+				for k, v := range getMethods(f.typ, ptr) {
 					res[k] = v
 				}
 			}
 		}
 		// Get all methods defined on this type.
 		for _, m := range typ.method {
+			if !ptr && isPtrRecv(m) {
+				continue
+			}
 			res[m.ident] = m.typ.TypeOf().String()
 		}
 		return res
 	}
 
-	return getMethods(t)
+	return getMethods(t, false)
+}
+
+// binMethodType returns the function type of the binary interface method
+// type rt, so it can be used as an interpreted interface method.
+func binMethodType(rt reflect.Type) *itype {
+	t := &itype{cat: funcT}
+	for i := 0; i < rt.NumIn(); i++ {
+		if rt.IsVariadic() && i == rt.NumIn()-1 {
+			t.arg = append(t.arg, &itype{cat: variadicT, val: &itype{cat: valueT, rtype: rt.In(i).Elem()}})
+			continue
+		}
+		t.arg = append(t.arg, &itype{cat: valueT, rtype: rt.In(i)})
+	}
+	for i := 0; i < rt.NumOut(); i++ {
+		t.ret = append(t.ret, &itype{cat: valueT, rtype: rt.Out(i)})
+	}
+	return t
+}
+
+// methodSignature returns the signature of the method m of the binary type t,
+// without the receiver, so it can be compared to interface methods.
+func methodSignature(t reflect.Type, m reflect.Method) string {
+	if t.Kind() == reflect.Interface {
+		return m.Type.String()
+	}
+	in := make([]reflect.Type, m.Type.NumIn()-1)
+	for i := range in {
+		in[i] = m.Type.In(i + 1)
+	}
+	out := make([]reflect.Type, m.Type.NumOut())
+	for i := range out {
+		out[i] = m.Type.Out(i)
+	}
+	return reflect.FuncOf(in, out, m.Type.IsVariadic()).String()
 }
 
 // id returns a unique type identificator string.
@@ -1148,6 +1309,17 @@ func (t *itype) fieldSeq(seq []int) *itype {
 	return ft
 }
 
+// embeddedInterface returns the prefix of the field path seq leading to the
+// first interpreted interface, or nil if the path does not cross an interface.
+func (t *itype) embeddedInterface(seq []int) []int {
+	for i := 1; i < len(seq); i++ {
+		if isInterfaceSrc(t.fieldSeq(seq[:i])) {
+			return seq[:i]
+		}
+	}
+	return nil
+}
+
 // lookupField returns a list of indices, i.e. a path to access a field in a struct object.
 func (t *itype) lookupField(name string) []int {
 	switch t.cat {
@@ -1382,7 +1554,7 @@ func (t *itype) refType(defined map[string]*itype, wrapRecursive bool) reflect.T
 			out[i] = v.refType(defined, true)
 		}
 		t.rtype = reflect.FuncOf(in, out, variadic)
-	case interfaceT:
+	case interfaceT, comparableT:
 		t.rtype = interf
 	case mapT:
 		t.rtype = reflect.MapOf(t.key.refType(defined, wrapRecursive), t.val.refType(defined, wrapRecursive))
@@ -1400,16 +1572,19 @@ func (t *itype) refType(defined map[string]*itype, wrapRecursive bool) reflect.T
 		// effort, and we're better off just waiting for
 		// https://github.com/golang/go/issues/39717 to land.
 		for _, f := range t.field {
+			ft := f.typ.refType(defined, wrapRecursive)
+			// Methods of embedded interfaces are not supported by reflect.StructOf:
+			// such fields are not marked anonymous, and calls are wrapped instead.
 			field := reflect.StructField{
-				Name: exportName(f.name), Type: f.typ.refType(defined, wrapRecursive),
-				Tag: reflect.StructTag(f.tag), Anonymous: (f.embed && !recursive),
+				Name: exportName(f.name), Type: ft,
+				Tag: reflect.StructTag(f.tag), Anonymous: (f.embed && !recursive && ft.Kind() != reflect.Interface),
 			}
 			fields = append(fields, field)
 		}
 		if recursive && wrapRecursive {
 			t.rtype = interf
 		} else {
-			t.rtype = reflect.StructOf(fields)
+			t.rtype = structOf(fields)
 		}
 	default:
 		if z, _ := t.zero(); z.IsValid() {
@@ -1419,6 +1594,39 @@ func (t *itype) refType(defined map[string]*itype, wrapRecursive bool) reflect.T
 	return t.rtype
 }
 
+// structOf returns reflect.StructOf(fields). Embedded types with methods are
+// only partially supported by reflect.StructOf: if it fails, such fields,
+// except the first one, then all of them, are not marked anonymous. Their
+// promoted fields and methods are still resolved by the interpreter.
+func structOf(fields []reflect.StructField) reflect.Type {
+	if t := tryStructOf(fields); t != nil {
+		return t
+	}
+	for i := 1; i < len(fields); i++ {
+		if fields[i].Anonymous && hasMethods(fields[i].Type) {
+			fields[i].Anonymous = false
+		}
+	}
+	if t := tryStructOf(fields); t != nil {
+		return t
+	}
+	if len(fields) > 0 && hasMethods(fields[0].Type) {
+		fields[0].Anonymous = false
+	}
+	return reflect.StructOf(fields)
+}
+
+// hasMethods returns true if type t or a pointer to t has methods.
+func hasMethods(t reflect.Type) bool {
+	return t.NumMethod() > 0 || t.Kind() != reflect.Ptr && reflect.PtrTo(t).NumMethod() > 0
+}
+
+// tryStructOf returns reflect.StructOf(fields), or nil if it panics.
+func tryStructOf(fields []reflect.StructField) (t reflect.Type) {
+	defer func() { _ = recover() }()
+	return reflect.StructOf(fields)
+}
+
 // TypeOf returns the reflection type of dynamic interpreter type t.
 func (t *itype) TypeOf() reflect.Type {
 	return t.refType(map[string]*itype{}, false)
@@ -1449,7 +1657,7 @@ func (t *itype) frameType() (r reflect.Type) {
 }
 
 func (t *itype) implements(it *itype) bool {
-	if t.cat == valueT {
+	if t.cat == valueT && !isInterfaceSrc(it) {
 		return t.TypeOf().Implements(it.TypeOf())
 	}
 	return t.methods().contains(it.methods())
@@ -1528,6 +1736,12 @@ func constToString(v reflect.Value) string {
 	return constant.StringVal(c)
 }
 
+// isPtrRecv returns true if the method definition n has a pointer receiver.
+func isPtrRecv(n *node) bool {
+	t := defRecvType(n)
+	return t != nil && t.cat == ptrT
+}
+
 func defRecvType(n *node) *itype {
 	if n.kind != funcDecl || len(n.child[0].child) == 0 {
 		return nil
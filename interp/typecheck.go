@@ -3,6 +3,7 @@ package interp
 import (
 	"errors"
 	"go/constant"
+	"go/token"
 	"math"
 	"reflect"
 )
@@ -190,7 +191,7 @@ func (check typecheck) comparison(n *node) error {
 		if typ.isNil() {
 			typ = c1.typ
 		}
-		return n.cfgErrorf("invalid operation: operator %v not defined on %s", n.action, typ.id(), ".")
+		return n.cfgErrorf("invalid operation: operator %v not defined on %s", n.action, typ.id())
 	}
 	return nil
 }
@@ -610,6 +611,8 @@ func (check typecheck) conversion(n *node, typ *itype) error {
 			}
 			n.rval = reflect.ValueOf(constant.MakeString(string(rune(codepoint))))
 			ok = true
+		case isNumber(t) && (isFloat(t) || constant.ToInt(c).Kind() == constant.Int):
+			return n.cfgErrorf("constant %s overflows %s", c, typ.id())
 		}
 
 	case n.typ.convertibleTo(typ):
@@ -713,6 +716,10 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 			return params[0].nod.cfgErrorf("first argument to append must be slice; have %s", typ.id())
 		}
 
+		if nparams == 1 {
+			return nil
+		}
+
 		// Special case append([]byte, "test"...) is allowed.
 		t1 := params[1].Type()
 		if nparams == 2 && ellipsis && t.Elem().Kind() == reflect.Uint8 && t1.TypeOf().Kind() == reflect.String {
@@ -770,12 +777,17 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 		case !typ0.untyped && typ1.untyped:
 			err = check.convertUntyped(p1.nod, typ0)
 		case typ0.untyped && typ1.untyped:
-			fltType := &itype{cat: float64T, name: "float64"}
-			err = check.convertUntyped(p0.nod, fltType)
-			if err != nil {
-				break
+			// The result is an untyped complex constant, made of parts
+			// representable as floating-point values.
+			for _, p := range []param{p0, p1} {
+				if !isNumber(p.Type().TypeOf()) {
+					return p.nod.cfgErrorf("invalid operation: arguments have type %s, expected floating-point", p.Type().id())
+				}
+				if err = check.representable(p.nod, reflect.TypeOf(float64(0))); err != nil {
+					return err
+				}
 			}
-			err = check.convertUntyped(p1.nod, fltType)
+			return nil
 		}
 		if err != nil {
 			return err
@@ -793,11 +805,12 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 		p := params[0]
 		typ := p.Type()
 		if typ.untyped {
-			if err := check.convertUntyped(p.nod, &itype{cat: complex128T, name: "complex128"}); err != nil {
-				return err
+			// The result is an untyped floating-point constant.
+			if !isNumber(typ.TypeOf()) {
+				return check.convertUntyped(p.nod, &itype{cat: complex128T, name: "complex128"})
 			}
+			return nil
 		}
-		typ = p.Type()
 		if !isComplex(typ.TypeOf()) {
 			return p.nod.cfgErrorf("invalid argument type %s for %s", typ.id(), name)
 		}
@@ -943,6 +956,9 @@ func (check typecheck) argument(p param, ftyp *itype, i, l int, ellipsis bool) e
 		if i != ftyp.numIn()-1 {
 			return p.nod.cfgErrorf("can only use ... with matching parameter")
 		}
+		if p.nod.typ != nil && p.nod.typ.cat == nilT {
+			return nil
+		}
 		t := p.Type().TypeOf()
 		if t.Kind() != reflect.Slice || !(&itype{cat: valueT, rtype: t.Elem()}).assignableTo(atyp) {
 			return p.nod.cfgErrorf("cannot use %s as type %s", p.nod.typ.id(), (&itype{cat: arrayT, val: atyp}).id())
@@ -1085,6 +1101,15 @@ func (check typecheck) representable(n *node, t reflect.Type) error {
 	return nil
 }
 
+// constValue checks that the constant result of an operation n, typed
+// from its destination, is representable in this type.
+func (check typecheck) constValue(n *node) error {
+	if n.typ.untyped || !n.rval.IsValid() || !isNumber(n.typ.TypeOf()) {
+		return nil
+	}
+	return check.representable(n, n.typ.TypeOf())
+}
+
 func (check typecheck) convertConst(v reflect.Value, t reflect.Type) (reflect.Value, error) {
 	if !v.IsValid() {
 		// TODO(nick): This should be an error as the const is in the frame which is undesirable.
@@ -1154,6 +1179,10 @@ func representableConst(c constant.Value, t reflect.Type) bool {
 			if _, ok := constant.Int64Val(x); !ok {
 				return false
 			}
+			// The range of a signed integer of n bits is [-1<<(n-1), 1<<(n-1)-1].
+			n := uint(bitlen[t.Kind()] - 1)
+			min := constant.UnaryOp(token.SUB, constant.Shift(constant.MakeInt64(1), token.SHL, n), 0)
+			return constant.BitLen(x) <= int(n) || constant.Compare(x, token.EQL, min)
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 			if _, ok := constant.Uint64Val(x); !ok {
 				return false
@@ -0,0 +1,20 @@
+// +build !go1.18
+
+package interp
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// typeParams returns the type parameters of a function type or type spec.
+// Type parameters are not produced by parsers prior to go1.18.
+func typeParams(n ast.Node) *ast.FieldList { return nil }
+
+// isIndexListExpr returns true if n is an index expression with several indices.
+// Such expressions are not produced by parsers prior to go1.18.
+func isIndexListExpr(n ast.Node) bool { return false }
+
+// isTilde returns true if tok is the ~ operator of constraint type terms.
+// This operator is not produced by scanners prior to go1.18.
+func isTilde(tok token.Token) bool { return false }
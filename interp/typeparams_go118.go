@@ -0,0 +1,29 @@
+// +build go1.18
+
+package interp
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// typeParams returns the type parameters of a function type or type spec, or nil.
+func typeParams(n ast.Node) *ast.FieldList {
+	switch a := n.(type) {
+	case *ast.FuncType:
+		return a.TypeParams
+	case *ast.TypeSpec:
+		return a.TypeParams
+	}
+	return nil
+}
+
+// isIndexListExpr returns true if n is an index expression with several
+// indices, i.e. the instantiation of a generic with several type arguments.
+func isIndexListExpr(n ast.Node) bool {
+	_, ok := n.(*ast.IndexListExpr)
+	return ok
+}
+
+// isTilde returns true if tok is the ~ operator of constraint type terms.
+func isTilde(tok token.Token) bool { return tok == token.TILDE }
@@ -38,6 +38,24 @@ func genValueRecvIndirect(n *node) func(*frame) reflect.Value {
 	return func(f *frame) reflect.Value { return v(f).Elem() }
 }
 
+// genValueRecvDynamic returns the receiver from the dynamic value of an
+// interface, dereferenced if the method does not have a pointer receiver.
+func genValueRecvDynamic(recv *receiver, ptr bool) func(*frame) reflect.Value {
+	return func(f *frame) reflect.Value {
+		r := recv.val
+		if len(recv.index) > 0 {
+			if r.Kind() == reflect.Ptr {
+				r = r.Elem()
+			}
+			r = r.FieldByIndex(recv.index)
+		}
+		if r.Kind() == reflect.Ptr && !ptr {
+			r = r.Elem()
+		}
+		return r
+	}
+}
+
 func genValueRecv(n *node) func(*frame) reflect.Value {
 	v := genValue(n.recv.node)
 	fi := n.recv.index
@@ -153,6 +171,27 @@ func genValueArray(n *node) func(*frame) reflect.Value {
 	return value
 }
 
+// genValueInterfaceElem returns the concrete value held by an interface,
+// either a binary interface or an interpreted one stored as a valueInterface.
+func genValueInterfaceElem(n *node) func(*frame) reflect.Value {
+	value := genValue(n)
+	if !isInterfaceSrc(n.typ) {
+		return func(f *frame) reflect.Value {
+			return value(f).Elem()
+		}
+	}
+	return func(f *frame) reflect.Value {
+		v := value(f)
+		if vi, ok := v.Interface().(valueInterface); ok {
+			if vi.value.IsValid() && vi.value.Kind() == reflect.Interface {
+				return vi.value.Elem()
+			}
+			return vi.value
+		}
+		return v.Elem()
+	}
+}
+
 func genValueRangeArray(n *node) func(*frame) reflect.Value {
 	value := genValue(n)
 
@@ -194,6 +233,10 @@ func genValueInterfaceArray(n *node) func(*frame) reflect.Value {
 		vi := value(f).Interface().([]valueInterface)
 		v := reflect.MakeSlice(reflect.TypeOf([]interface{}{}), len(vi), len(vi))
 		for i, vv := range vi {
+			if !vv.value.IsValid() {
+				// Zero valueInterface, keep the nil interface.
+				continue
+			}
 			v.Index(i).Set(vv.value)
 		}
 
@@ -201,6 +244,53 @@ func genValueInterfaceArray(n *node) func(*frame) reflect.Value {
 	}
 }
 
+// genValueInterfaceMap returns a copy of the map n where interpreted
+// interface keys and values are replaced by their concrete value, as
+// expected by binary code.
+func genValueInterfaceMap(n *node) func(*frame) reflect.Value {
+	value := genValue(n)
+	return func(f *frame) reflect.Value {
+		return concreteMap(value(f))
+	}
+}
+
+func concreteMap(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return v
+	}
+	t := v.Type()
+	m := reflect.MakeMapWithSize(t, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		m.SetMapIndex(concreteValue(iter.Key(), t.Key()), concreteValue(iter.Value(), t.Elem()))
+	}
+	return m
+}
+
+// concreteValue returns the concrete value of v if it holds an interpreted
+// interface, or v unchanged. Nested slices and maps of interpreted
+// interfaces are converted as well. Nil interfaces are returned as zero
+// values of type t.
+func concreteValue(v reflect.Value, t reflect.Type) reflect.Value {
+	vi, ok := v.Interface().(valueInterface)
+	if !ok {
+		return v
+	}
+	switch v = vi.value; {
+	case !v.IsValid() || vi.node == nil:
+		return reflect.Zero(t)
+	case v.Kind() == reflect.Map && v.Type().Elem().Kind() == reflect.Interface:
+		return concreteMap(v)
+	case v.Type() == reflect.TypeOf([]valueInterface{}):
+		s := reflect.MakeSlice(reflect.TypeOf([]interface{}{}), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(concreteValue(v.Index(i), interf))
+		}
+		return s
+	}
+	return v
+}
+
 func genValueInterface(n *node) func(*frame) reflect.Value {
 	value := genValue(n)
 
@@ -208,6 +298,15 @@ func genValueInterface(n *node) func(*frame) reflect.Value {
 		v := value(f)
 		nod := n
 		for v.IsValid() {
+			if v.Kind() == reflect.Interface && nod.typ.cat == valueT {
+				// Binary interface value: use its dynamic type and value.
+				if v.IsNil() {
+					return reflect.ValueOf(valueInterface{})
+				}
+				v = v.Elem()
+				nod = &node{typ: &itype{cat: valueT, rtype: v.Type()}}
+				continue
+			}
 			// traverse interface indirections to find out concrete type
 			vi, ok := v.Interface().(valueInterface)
 			if !ok {
@@ -220,6 +319,22 @@ func genValueInterface(n *node) func(*frame) reflect.Value {
 	}
 }
 
+// hostValueInterface returns the interpreted interface value holding the
+// dynamic value of v, an interface value provided by the host. The binary
+// dynamic type is kept, so type switches and assertions apply to it.
+func hostValueInterface(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return reflect.ValueOf(valueInterface{})
+	}
+	if vi, ok := v.Interface().(valueInterface); ok {
+		return reflect.ValueOf(vi)
+	}
+	return reflect.ValueOf(valueInterface{&node{typ: &itype{cat: valueT, rtype: v.Type()}}, v})
+}
+
 func zeroInterfaceValue() reflect.Value {
 	n := &node{kind: basicLit, typ: &itype{cat: nilT, untyped: true}}
 	v := reflect.New(interf).Elem()
@@ -258,6 +373,44 @@ func genValueInterfaceValue(n *node) func(*frame) reflect.Value {
 	}
 }
 
+// genValueInterfaceWrapper returns the concrete value of the interpreted
+// interface n, wrapped if necessary to implement the binary interface typ.
+func genValueInterfaceWrapper(n *node, typ reflect.Type) func(*frame) reflect.Value {
+	value := genValue(n)
+
+	return func(f *frame) reflect.Value {
+		vi := value(f).Interface().(valueInterface)
+		if vi.node == nil || !vi.value.IsValid() {
+			return reflect.New(typ).Elem()
+		}
+		if vi.value.Type().Implements(typ) {
+			return vi.value
+		}
+		return wrapInterfaceValue(n, f, vi, typ)
+	}
+}
+
+// genChanElem returns a function converting a value received from a channel
+// to the frame representation of n. Values sent by binary code on a channel
+// of interpreted interface are wrapped in a valueInterface.
+func genChanElem(n *node) func(reflect.Value) reflect.Value {
+	if n.typ == nil || !isInterfaceSrc(n.typ) {
+		return func(v reflect.Value) reflect.Value { return v }
+	}
+	return func(v reflect.Value) reflect.Value {
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if !v.IsValid() {
+			return reflect.New(valueInterfaceType).Elem()
+		}
+		if v.Type() == valueInterfaceType {
+			return v
+		}
+		return reflect.ValueOf(valueInterface{n, v})
+	}
+}
+
 func genValueNode(n *node) func(*frame) reflect.Value {
 	value := genValue(n)
 
@@ -444,6 +597,25 @@ func genValueUint(n *node) func(*frame) (reflect.Value, uint64) {
 	return nil
 }
 
+// genValueShift returns a function which returns the value of a shift count.
+// It panics at execution if the count is negative.
+func genValueShift(n *node) func(*frame) (reflect.Value, uint64) {
+	value := genValue(n)
+
+	switch n.typ.TypeOf().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(f *frame) (reflect.Value, uint64) {
+			v := value(f)
+			i := v.Int()
+			if i < 0 {
+				panic(runtimeError("negative shift amount"))
+			}
+			return v, uint64(i)
+		}
+	}
+	return genValueUint(n)
+}
+
 func genValueFloat(n *node) func(*frame) (reflect.Value, float64) {
 	value := genValue(n)
 